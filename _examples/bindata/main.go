@@ -28,14 +28,23 @@ func main() {
 		DisableCache: true,
 	}
 
-	staticHandler := http.FileServer(&assetfs.AssetFS{Asset: staticdata.Asset, AssetDir: staticdata.AssetDir, AssetInfo: staticdata.AssetInfo, Prefix: "static"})
-	router.GetSH("/static/*", http.StripPrefix("/static/", staticHandler))
+	staticFS := &assetfs.AssetFS{Asset: staticdata.Asset, AssetDir: staticdata.AssetDir, AssetInfo: staticdata.AssetInfo, Prefix: "static"}
+	staticHandler, err := goview.NewStatic(staticFS, goview.StaticConfig{Prefix: "/static/"})
+	if err != nil {
+		panic(err)
+	}
+	router.GetSH("/static/*", staticHandler)
 
 	templateFS := &assetfs.AssetFS{Asset: viewsdata.Asset, AssetDir: viewsdata.AssetDir, AssetInfo: viewsdata.AssetInfo, Prefix: "views"}
 
 	//new template engine
 	e = bindata.NewWithConfig(templateFS, config)
 
+	//hashed static names for {{asset "css/bootstrap.css"}}
+	if err := e.UseAssets(staticHandler.Manifest(false)); err != nil {
+		panic(err)
+	}
+
 	router.Get("/", h_home)
 	router.Get("/page", h_page)
 
@@ -0,0 +1,150 @@
+package goview
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// AssetsPack is the func pack name used by UseAssets.
+const AssetsPack = "assets"
+
+// AssetConfig struct
+type AssetConfig struct {
+	Manifest string `yaml:"manifest"` //manifest file under Root, such as "manifest.json"
+	Prefix   string `yaml:"prefix"`   //url prefix of assets, such as "/static/"
+	Dev      bool   `yaml:"dev"`      //dev mode, use unhashed asset paths
+}
+
+// AssetManifest maps logical asset paths, such as `css/app.css`, to fingerprinted urls.
+type AssetManifest struct {
+	prefix string
+	dev    bool
+	files  map[string]string
+}
+
+// NewAssetManifest creates a manifest from logical to hashed paths. In dev mode
+// the manifest is ignored and urls are built from the unhashed paths.
+func NewAssetManifest(prefix string, files map[string]string, dev bool) *AssetManifest {
+	m := &AssetManifest{
+		prefix: prefix,
+		dev:    dev,
+		files:  make(map[string]string, len(files)),
+	}
+	for k, v := range files {
+		m.files[cleanAssetPath(k)] = v
+	}
+	return m
+}
+
+// ParseAssetManifest parses a manifest produced by Vite (`manifest.json`),
+// webpack (webpack-manifest-plugin, webpack-assets-manifest) or a plain
+// JSON object of logical to hashed paths.
+func ParseAssetManifest(data []byte) (map[string]string, error) {
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("ViewEngine asset manifest error: %v", err)
+	}
+
+	files := make(map[string]string, len(raw))
+	for name, value := range raw {
+		var file string
+		if err := json.Unmarshal(value, &file); err == nil {
+			// webpack and plain manifests: {"css/app.css": "css/app.3f9a1c.css"}
+			files[name] = file
+			continue
+		}
+		// Vite: {"src/main.js": {"file": "assets/main.4889e940.js"}}
+		// webpack-assets-manifest with integrity: {"main.js": {"src": "main.3f9a1c.js"}}
+		var entry struct {
+			File string `json:"file"`
+			Src  string `json:"src"`
+		}
+		if err := json.Unmarshal(value, &entry); err != nil {
+			continue
+		}
+		if entry.File != "" {
+			files[name] = entry.File
+		} else if entry.Src != "" {
+			files[name] = entry.Src
+		}
+	}
+	return files, nil
+}
+
+// Lookup returns the hashed path of a logical asset path.
+func (m *AssetManifest) Lookup(name string) (string, bool) {
+	file, ok := m.files[cleanAssetPath(name)]
+	return file, ok
+}
+
+// URL returns the fingerprinted url of a logical asset path. In dev mode it
+// returns the unhashed path.
+func (m *AssetManifest) URL(name string) (string, error) {
+	if m.dev {
+		return m.join(cleanAssetPath(name)), nil
+	}
+	file, ok := m.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("ViewEngine asset %q not found in manifest", name)
+	}
+	return m.join(file), nil
+}
+
+// FuncMap returns the `asset` template func, usage: {{asset "css/app.css"}}
+func (m *AssetManifest) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"asset": m.URL,
+	}
+}
+
+func (m *AssetManifest) join(file string) string {
+	if strings.HasPrefix(file, "/") || strings.Contains(file, "://") || m.prefix == "" {
+		return file
+	}
+	return strings.TrimSuffix(m.prefix, "/") + "/" + strings.TrimPrefix(file, "./")
+}
+
+// LoadAssets reads the manifest from the same file source as the views and
+// registers the `asset` template func. The manifest is not read in dev mode.
+func (e *ViewEngine) LoadAssets(config AssetConfig) (*AssetManifest, error) {
+	files := make(map[string]string)
+	if !config.Dev {
+		data, err := e.readFile(config.Manifest)
+		if err != nil {
+			return nil, fmt.Errorf("ViewEngine asset manifest read error: %v", err)
+		}
+		files, err = ParseAssetManifest(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	m := NewAssetManifest(config.Prefix, files, config.Dev)
+	if err := e.UseAssets(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UseAssets registers the `asset` template func of the manifest as the "assets" func pack.
+func (e *ViewEngine) UseAssets(m *AssetManifest) error {
+	if err := e.RegisterFuncs(AssetsPack, m.FuncMap()); err != nil {
+		return err
+	}
+	e.funcMutex.Lock()
+	e.assets = m
+	e.funcMutex.Unlock()
+	return nil
+}
+
+// assetManifest returns the manifest set by UseAssets, or nil.
+func (e *ViewEngine) assetManifest() *AssetManifest {
+	e.funcMutex.RLock()
+	defer e.funcMutex.RUnlock()
+	return e.assets
+}
+
+func cleanAssetPath(name string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(name, "./"), "/")
+}
@@ -0,0 +1,103 @@
+package goview
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"html"
+	"net/http"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseAssetManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		asset    string
+		want     string
+	}{
+		{"plain", `{"css/app.css": "css/app.3f9a1c.css"}`, "css/app.css", "/static/css/app.3f9a1c.css"},
+		{"webpack", `{"main.js": "/static/main.3f9a1c.js"}`, "main.js", "/static/main.3f9a1c.js"},
+		{"vite", `{"src/main.js": {"file": "assets/main.4889e940.js", "src": "src/main.js", "isEntry": true, "css": ["assets/main.b82dbe22.css"]}}`, "/src/main.js", "/static/assets/main.4889e940.js"},
+		{"assets-manifest", `{"app.js": {"src": "app.3f9a1c.js", "integrity": "sha384-x"}, "entrypoints": {"app": {"assets": {}}}}`, "app.js", "/static/app.3f9a1c.js"},
+	}
+	for _, tt := range tests {
+		files, err := ParseAssetManifest([]byte(tt.manifest))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got, err := NewAssetManifest("/static/", files, false).URL(tt.asset)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: URL(%q) = %q, %v; want %q", tt.name, tt.asset, got, err, tt.want)
+		}
+	}
+}
+
+func TestLoadAssets(t *testing.T) {
+	files := map[string]string{
+		"manifest.json": `{"css/app.css": "css/app.3f9a1c.css"}`,
+		"index.html":    `<link href="{{asset "css/app.css"}}">`,
+		"missing.html":  `{{asset "css/missing.css"}}`,
+	}
+	e := newTestEngine(Config{Root: "views", Extension: ".html"}, files)
+	if _, err := e.LoadAssets(AssetConfig{Manifest: "manifest.json", Prefix: "/static"}); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := e.RenderWriter(buf, "index.html", nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<link href="/static/css/app.3f9a1c.css">`; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if err := e.RenderWriter(new(bytes.Buffer), "missing.html", nil); err == nil {
+		t.Error("expected error for an asset missing from the manifest")
+	}
+
+	dev := newTestEngine(Config{Root: "views", Extension: ".html"}, files)
+	if _, err := dev.LoadAssets(AssetConfig{Manifest: "nothing.json", Prefix: "/static/", Dev: true}); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := dev.RenderWriter(buf, "index.html", nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<link href="/static/css/app.css">`; buf.String() != want {
+		t.Errorf("dev: got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestUseIntegrity(t *testing.T) {
+	js := []byte("console.log(1)")
+	sum := sha512.Sum384(js)
+	sri := "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+
+	fsys := fstest.MapFS{"assets/main.4889e940.js": {Data: js}, "js/app.js": {Data: js}}
+	e := newTestEngine(Config{Extension: ".html"}, map[string]string{
+		"vite.html":  `{{scriptTag "src/main.js"}}`,
+		"plain.html": `{{styleTag "js/app.js"}}<script integrity="{{integrity "js/app.js"}}"></script>`,
+	})
+	if err := e.UseIntegrity(http.FS(fsys), "/static"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := e.RenderWriter(buf, "plain.html", nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<link rel="stylesheet" href="/static/js/app.js" integrity="` + sri + `" crossorigin="anonymous"><script integrity="` + sri + `"></script>`; html.UnescapeString(buf.String()) != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	if err := e.UseAssets(NewAssetManifest("/static/", map[string]string{"src/main.js": "assets/main.4889e940.js"}, false)); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := e.RenderWriter(buf, "vite.html", nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<script src="/static/assets/main.4889e940.js" integrity="` + sri + `" crossorigin="anonymous"></script>`; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-tea/goview/site"
+)
+
+func runBuild(args []string, stdout io.Writer) error {
+	fs := newFlagSet("build")
+	file := fs.String("f", "site.yaml", "site file")
+	output := fs.String("o", "", "output directory, overrides the site file")
+	quiet := fs.Bool("q", false, "do not list the written files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := site.Load(*file)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		s.Output = *output
+	}
+	files, err := s.Build()
+	if !*quiet {
+		for _, f := range files {
+			fmt.Fprintln(stdout, f)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "built %d files in %s\n", len(files), s.Output)
+	return nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+func runConfig(args []string, stdout io.Writer) error {
+	fs := newFlagSet("config")
+	ef := addEngineFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	config, err := ef.load()
+	if err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(out)
+	return err
+}
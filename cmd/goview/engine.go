@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template/parse"
+
+	"github.com/go-tea/goview"
+	"github.com/go-tea/goview/markdown"
+)
+
+// defaultConfigFile is loaded when it exists and no -c flag is given.
+const defaultConfigFile = "goview.yaml"
+
+// engineFlags are the flags of the commands using a view engine.
+type engineFlags struct {
+	config *string
+	root   *string
+	funcs  *string
+}
+
+func addEngineFlags(fs *flag.FlagSet) *engineFlags {
+	return &engineFlags{
+		config: fs.String("c", "", "config file, "+defaultConfigFile+" when it exists"),
+		root:   fs.String("root", "", "view root, overrides the config file"),
+		funcs:  fs.String("funcs", "", "comma separated names of the app funcs, stubbed as func(...interface{}) string returning \"\""),
+	}
+}
+
+// load returns the effective config. A relative root of the config file is relative to the
+// config file, as in site files, the -root flag to the working directory.
+func (f *engineFlags) load() (goview.Config, error) {
+	file := *f.config
+	if file == "" {
+		if _, err := os.Stat(defaultConfigFile); err == nil {
+			file = defaultConfigFile
+		}
+	}
+	config := goview.DefaultConfig
+	if file != "" {
+		var err error
+		if config, err = goview.LoadConfig(file); err != nil {
+			return config, err
+		}
+		if config.Root != "" && !filepath.IsAbs(config.Root) {
+			config.Root = filepath.Join(filepath.Dir(file), config.Root)
+		}
+	}
+	if *f.root != "" {
+		config.Root = *f.root
+	}
+	return config, nil
+}
+
+// engine returns an engine of the effective config, with markdown and the stub funcs of -funcs.
+func (f *engineFlags) engine() (*goview.ViewEngine, goview.Config, error) {
+	config, err := f.load()
+	if err != nil {
+		return nil, config, err
+	}
+	e := goview.New(config)
+	if err := markdown.Use(e); err != nil {
+		return nil, config, err
+	}
+	if *f.funcs != "" {
+		stubs := make(map[string]interface{})
+		for _, name := range strings.Split(*f.funcs, ",") {
+			stubs[strings.TrimSpace(name)] = func(...interface{}) string { return "" }
+		}
+		if err := e.RegisterFuncs("cli", stubs); err != nil {
+			return nil, config, err
+		}
+	}
+	return e, config, nil
+}
+
+// templateFile is a template or markdown file under the view root.
+type templateFile struct {
+	file     string //path with extension, relative to the root
+	name     string //view name rendering with master, without template extension
+	markdown bool
+}
+
+// templateFiles lists the files under the root with an accepted extension or the markdown extension.
+func templateFiles(config goview.Config) ([]templateFile, error) {
+	exts := config.Extensions
+	if len(exts) == 0 {
+		exts = []string{config.Extension}
+	}
+	var files []templateFile
+	err := fs.WalkDir(os.DirFS(config.Root), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := path.Ext(p)
+		if config.Markdown != "" && ext == config.Markdown {
+			files = append(files, templateFile{file: p, name: p, markdown: true})
+			return nil
+		}
+		for _, accepted := range exts {
+			if accepted != "" && ext == accepted {
+				files = append(files, templateFile{file: p, name: strings.TrimSuffix(p, ext)})
+				return nil
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.New("view root " + config.Root + " does not exist")
+	}
+	return files, err
+}
+
+// templateRefs returns the templates referenced by `include "name"` and `{{template "name"}}` in a file,
+// also inside its `{{define}}` blocks.
+func templateRefs(config goview.Config, f templateFile) ([]string, error) {
+	if f.markdown {
+		return nil, nil
+	}
+	src, err := os.ReadFile(filepath.Join(config.Root, filepath.FromSlash(f.file)))
+	if err != nil {
+		return nil, err
+	}
+	tree := parse.New(f.file)
+	tree.Mode = parse.SkipFuncCheck
+	treeSet := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(string(src), config.Delims.Left, config.Delims.Right, treeSet); err != nil {
+		return nil, err
+	}
+	var refs []string
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n != nil {
+				for _, c := range n.Nodes {
+					walk(c)
+				}
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				for i, arg := range cmd.Args {
+					if id, ok := arg.(*parse.IdentifierNode); ok && id.Ident == "include" && i+1 < len(cmd.Args) {
+						if s, ok := cmd.Args[i+1].(*parse.StringNode); ok {
+							refs = append(refs, s.Text)
+						}
+					}
+					walk(arg)
+				}
+			}
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			refs = append(refs, n.Name)
+			walk(n.Pipe)
+		}
+	}
+	// the set holds the file tree and one tree per define
+	for _, t := range treeSet {
+		walk(t.Root)
+	}
+	return refs, nil
+}
+
+// layoutFiles returns the names of the masters and partials, from the config, the front matter
+// of views and the templates included by other templates. The templates included by a file
+// failing to parse are unknown, the parse errors of all files are returned.
+func layoutFiles(e *goview.ViewEngine, config goview.Config, files []templateFile) (map[string]bool, error) {
+	layouts := make(map[string]bool)
+	var errs []error
+	if config.Master != "" {
+		layouts[config.Master] = true
+	}
+	for _, p := range config.Partials {
+		layouts[p] = true
+	}
+	for _, f := range files {
+		meta, err := e.Meta(f.file)
+		if err != nil {
+			continue
+		}
+		if meta.Layout != nil && *meta.Layout != "" {
+			layouts[*meta.Layout] = true
+		}
+		for _, p := range meta.Partials {
+			layouts[p] = true
+		}
+		refs, err := templateRefs(config, f)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.file, err))
+		}
+		for _, ref := range refs {
+			layouts[ref] = true
+		}
+	}
+	return layouts, errors.Join(errs...)
+}
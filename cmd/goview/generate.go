@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-tea/goview"
+)
+
+func runGenerate(args []string, stdout io.Writer) error {
+	fs := newFlagSet("generate")
+	ef := addEngineFlags(fs)
+	output := fs.String("o", "goview_views.go", "output Go file")
+	pkg := fs.String("pkg", "", "package name, $GOPACKAGE of go generate or views by default")
+	noValidate := fs.Bool("novalidate", false, "do not validate the templates")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	e, config, err := ef.engine()
+	if err != nil {
+		return err
+	}
+	if *pkg == "" {
+		*pkg = os.Getenv("GOPACKAGE")
+	}
+	if *pkg == "" {
+		*pkg = "views"
+	}
+
+	if !*noValidate {
+		files, err := templateFiles(config)
+		if err != nil {
+			return err
+		}
+		layouts, err := layoutFiles(e, config, files)
+		if err != nil {
+			return err
+		}
+		failed := 0
+		for _, f := range files {
+			if err := lintFile(e, f, layouts); err != nil {
+				fmt.Fprintf(stdout, "%s: %v\n", f.file, err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d templates failed, nothing generated", failed, len(files))
+		}
+	}
+
+	files, err := readRoot(config.Root, *output)
+	if err != nil {
+		return err
+	}
+	src, err := generate(*pkg, config, files)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "generated %s with %d files of %s\n", *output, len(files), config.Root)
+	return nil
+}
+
+// readRoot reads the regular files under root. Hidden files and directories, Go files and the
+// output file are skipped, so an output inside root is not embedded by the next run.
+func readRoot(root, output string) (map[string]string, error) {
+	output, err := filepath.Abs(output)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	err = fs.WalkDir(os.DirFS(root), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || filepath.Ext(p) == ".go" {
+			return nil
+		}
+		file := filepath.Join(root, filepath.FromSlash(p))
+		if abs, err := filepath.Abs(file); err != nil || abs == output {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		files[p] = string(data)
+		return nil
+	})
+	return files, err
+}
+
+// generate returns the gofmt'ed source of the package embedding files.
+func generate(pkg string, config goview.Config, files map[string]string) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Code generated by goview generate; DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+	fmt.Fprintf(buf, "import \"github.com/go-tea/goview\"\n\n")
+	fmt.Fprintf(buf, "// Files are the files of the view root %s, keyed by their path relative to the root.\n", strconv.Quote(filepath.ToSlash(config.Root)))
+	fmt.Fprintf(buf, "var Files = map[string]string{\n")
+	for _, name := range names {
+		fmt.Fprintf(buf, "\t%s: %s,\n", strconv.Quote(name), strconv.Quote(files[name]))
+	}
+	fmt.Fprintf(buf, "}\n\n")
+	fmt.Fprintf(buf, "// FileHandler returns a goview.FileHandler reading Files, for ViewEngine.SetFileHandler.\n")
+	fmt.Fprintf(buf, "func FileHandler() goview.FileHandler {\n\treturn goview.MapFileHandler(Files)\n}\n")
+	return format.Source(buf.Bytes())
+}
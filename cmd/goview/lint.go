@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/go-tea/goview"
+)
+
+func runLint(args []string, stdout io.Writer) error {
+	fs := newFlagSet("lint")
+	ef := addEngineFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	e, config, err := ef.engine()
+	if err != nil {
+		return err
+	}
+	files, err := templateFiles(config)
+	if err != nil {
+		return err
+	}
+
+	layouts, err := layoutFiles(e, config, files)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, f := range files {
+		if err := lintFile(e, f, layouts); err != nil {
+			fmt.Fprintf(stdout, "%s: %v\n", f.file, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d templates failed", failed, len(files))
+	}
+	fmt.Fprintf(stdout, "%d templates ok\n", len(files))
+	return nil
+}
+
+// lintFile parses a file alone, then a view with its master. Templates missing at escaping are
+// ignored: masters and partials reference templates of the views, and a view referencing templates
+// missing from the master is a page rendered without master, as `page.html`.
+func lintFile(e *goview.ViewEngine, f templateFile, layouts map[string]bool) error {
+	err := e.Parse(f.file)
+	if err == nil && !f.markdown && !layouts[f.name] {
+		err = e.Parse(f.name)
+	}
+	if noSuchTemplate(err) {
+		return nil
+	}
+	return err
+}
+
+func noSuchTemplate(err error) bool {
+	var escapeErr *template.Error
+	return errors.As(err, &escapeErr) && escapeErr.ErrorCode == template.ErrNoSuchTemplate
+}
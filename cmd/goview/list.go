@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+func runList(args []string, stdout io.Writer) error {
+	fs := newFlagSet("list")
+	ef := addEngineFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	e, config, err := ef.engine()
+	if err != nil {
+		return err
+	}
+	files, err := templateFiles(config)
+	if err != nil {
+		return err
+	}
+	layouts, err := layoutFiles(e, config, files)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VIEW\tMASTER\tPARTIALS\tTITLE")
+	for _, f := range files {
+		if layouts[f.name] {
+			continue
+		}
+		meta, err := e.Meta(f.file)
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t%v\t\t\n", f.name, err)
+			continue
+		}
+		master := config.Master
+		if meta.Layout != nil {
+			master = *meta.Layout
+		}
+		partials := append(append([]string{}, config.Partials...), meta.Partials...)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.name, orDash(master), orDash(strings.Join(partials, ",")), meta.Title)
+	}
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+// Command goview works with goview templates without writing Go.
+//
+// Usage:
+//
+//	goview <command> [flags]
+//
+// The commands are:
+//
+//	build     pre-render a static site from a site file
+//	config    print the effective config
+//	generate  write the views into a Go source file for embedding
+//	lint      parse and validate all templates under the view root
+//	list      list the views with their masters and partials
+//	render    render a view to stdout with data from a JSON or YAML file
+//
+// The commands using views read goview.yaml when it exists, or the file of the -c flag.
+// A relative root in the config file is relative to the config file.
+//
+// Run `goview <command> -h` for the flags of a command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a subcommand of goview.
+type command struct {
+	usage string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"build":    {"pre-render a static site from a site file", runBuild},
+	"config":   {"print the effective config", runConfig},
+	"generate": {"write the views into a Go source file for embedding", runGenerate},
+	"lint":     {"parse and validate all templates under the view root", runLint},
+	"list":     {"list the views with their masters and partials", runList},
+	"render":   {"render a view to stdout with data from a JSON or YAML file", runRender},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "goview: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+	if err := cmd.run(args[1:], stdout); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(stderr, "goview %s: %v\n", args[0], err)
+		}
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: goview <command> [flags]\n\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].usage)
+	}
+}
+
+// newFlagSet returns the flag set of a command, errors are returned by Parse.
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("goview "+name, flag.ContinueOnError)
+}
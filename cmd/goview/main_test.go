@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-tea/goview"
+)
+
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"goview.yaml":               "root: " + filepath.Join(dir, "views") + "\nextension: .tpl\nmaster: layouts/master\n",
+		"views/layouts/master.tpl":  `<title>{{.title}}</title>{{template "content" .}}{{include "partials/footer"}}`,
+		"views/partials/footer.tpl": `<footer>{{year}}</footer>`,
+		"views/index.tpl":           "---\ntitle: Home\n---\n{{define \"content\"}}<h1>{{.title}}</h1>{{end}}",
+		"views/page.tpl":            `<p>{{.title}}</p>`,
+		"data.yaml":                 "title: Tom & Jerry\n",
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), 0755)
+		os.WriteFile(file, []byte(content), 0644)
+	}
+	config := filepath.Join(dir, "goview.yaml")
+
+	for _, tt := range []struct {
+		args []string
+		code int
+		want string
+	}{
+		{[]string{"lint", "-c", config}, 1, `function "year" not defined`},
+		{[]string{"lint", "-c", config, "-funcs", "year"}, 0, "4 templates ok"},
+		{[]string{"list", "-c", config, "-funcs", "year"}, 0, "index  layouts/master  -         Home"},
+		{[]string{"render", "-c", config, "-funcs", "year", "-data", filepath.Join(dir, "data.yaml"), "page.tpl"}, 0, "<p>Tom &amp; Jerry</p>"},
+		{[]string{"config", "-c", config, "-root", "templates"}, 0, "root: templates\nmaster: layouts/master"},
+		{[]string{"generate", "-c", config, "-o", filepath.Join(dir, "views_gen.go")}, 1, "nothing generated"},
+		{[]string{"generate", "-c", config, "-funcs", "year", "-pkg", "tpl", "-o", filepath.Join(dir, "views_gen.go")}, 0, "with 4 files"},
+		{[]string{"unknown"}, 2, `unknown command "unknown"`},
+	} {
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		code := run(tt.args, stdout, stderr)
+		out := stdout.String() + stderr.String()
+		if code != tt.code || !strings.Contains(out, tt.want) {
+			t.Errorf("%v: exit %d, output %q, want %d %q", tt.args, code, out, tt.code, tt.want)
+		}
+	}
+}
+
+func TestConfigRelativeRoot(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"site/goview.yaml":      "root: views\nmaster: \"\"\n",
+		"site/views/index.html": `<p>{{.}}</p>`,
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), 0755)
+		os.WriteFile(file, []byte(content), 0644)
+	}
+
+	// the root is found from another working directory
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	code := run([]string{"lint", "-c", filepath.Join(dir, "site", "goview.yaml")}, stdout, stderr)
+	out := stdout.String() + stderr.String()
+	if code != 0 || !strings.Contains(out, "1 templates ok") {
+		t.Errorf("exit %d, output %q", code, out)
+	}
+}
+
+func TestLintParseError(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"views/layouts/master.html": `<main>{{template "content" .}}</main>`,
+		"views/index.html":          `{{define "content"}}{{include "partials/box"}}{{if}}{{end}}`,
+		"views/partials/box.html":   `<div>{{.}}</div>`,
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), 0755)
+		os.WriteFile(file, []byte(content), 0644)
+	}
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	code := run([]string{"lint", "-root", filepath.Join(dir, "views")}, stdout, stderr)
+	out := stdout.String() + stderr.String()
+	if code != 1 || !strings.Contains(out, "index.html: template: index.html:1: missing value for if") {
+		t.Errorf("exit %d, output %q", code, out)
+	}
+}
+
+func TestListDefineInclude(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"views/layouts/master.html": `<main>{{template "content" .}}</main>`,
+		"views/index.html":          `{{define "content"}}{{include "partials/card"}}{{end}}`,
+		"views/partials/card.html":  `<div>card</div>`,
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), 0755)
+		os.WriteFile(file, []byte(content), 0644)
+	}
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	code := run([]string{"list", "-root", filepath.Join(dir, "views")}, stdout, stderr)
+	out := stdout.String() + stderr.String()
+	if code != 0 || !strings.Contains(out, "index") || strings.Contains(out, "partials/card") {
+		t.Errorf("exit %d, output %q", code, out)
+	}
+}
+
+func TestGenerateTwice(t *testing.T) {
+	dir := t.TempDir()
+	views := filepath.Join(dir, "views")
+	os.MkdirAll(views, 0755)
+	os.WriteFile(filepath.Join(views, "index.html"), []byte(`<p>{{.}}</p>`), 0644)
+	os.WriteFile(filepath.Join(views, "doc.go"), []byte("package views\n"), 0644)
+
+	// the output inside the root is not embedded by the next run
+	output := filepath.Join(views, "views_gen.txt")
+	var generated []string
+	for i := 0; i < 2; i++ {
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		if code := run([]string{"generate", "-root", views, "-novalidate", "-o", output}, stdout, stderr); code != 0 {
+			t.Fatalf("exit %d: %s%s", code, stdout, stderr)
+		}
+		src, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		generated = append(generated, string(src))
+	}
+	if generated[0] != generated[1] {
+		t.Errorf("second run differs:\n%s\n---\n%s", generated[0], generated[1])
+	}
+	if strings.Contains(generated[1], "views_gen") || strings.Contains(generated[1], "doc.go") {
+		t.Errorf("generated source embeds Go files:\n%s", generated[1])
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("views", goview.Config{Root: "views"}, map[string]string{
+		"index.html":          "{{define \"content\"}}`x`\n{{end}}",
+		"layouts/master.html": "<main>{{template \"content\" .}}</main>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "views_gen.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name.Name != "views" || !strings.HasPrefix(string(src), "// Code generated by goview generate; DO NOT EDIT.") {
+		t.Errorf("unexpected source:\n%s", src)
+	}
+	if !strings.Contains(string(src), `"index.html":          "{{define \"content\"}}`+"`x`"+`\n{{end}}",`) {
+		t.Errorf("unexpected source:\n%s", src)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"io"
+
+	"github.com/go-tea/goview/site"
+)
+
+func runRender(args []string, stdout io.Writer) error {
+	fs := newFlagSet("render")
+	ef := addEngineFlags(fs)
+	dataFile := fs.String("data", "", "JSON or YAML file of the render data")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: goview render [flags] <view>")
+	}
+	e, _, err := ef.engine()
+	if err != nil {
+		return err
+	}
+	var data interface{}
+	if *dataFile != "" {
+		if data, err = site.LoadData(*dataFile); err != nil {
+			return err
+		}
+	}
+	return e.RenderWriter(stdout, fs.Arg(0), data)
+}
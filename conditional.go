@@ -0,0 +1,130 @@
+package goview
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LastModifiedKey is the key of a time.Time in M data used for Last-Modified.
+const LastModifiedKey = "lastModified"
+
+// LastModifier is implemented by render data knowing its modification time.
+type LastModifier interface {
+	LastModified() time.Time
+}
+
+// RenderRequest renders like Render and, when Config.ETag or Config.LastModified
+// is set, buffers the output to answer conditional requests:
+//
+//   - ETag: a hash of the rendered bytes, If-None-Match is answered with 304 Not Modified.
+//   - Last-Modified: the latest of the data time, from a LastModifier or a time.Time under
+//     LastModifiedKey in M data, and the modification times of the view, its master and
+//     its partials, see SetModTimeHandler. If-Modified-Since is answered with 304 Not Modified.
+//     When a template time is unknown no Last-Modified is sent.
+//
+// Conditional requests are only answered for GET and HEAD with status 200. Note that
+// a Config.CSP nonce makes every render unique, so the ETag never matches.
+func (e *ViewEngine) RenderRequest(w http.ResponseWriter, r *http.Request, statusCode int, name string, data interface{}) error {
+	if !e.config.ETag && !e.config.LastModified {
+		return e.Render(w, statusCode, name, data)
+	}
+
+	header := w.Header()
+	rs := new(renderState)
+	master, viewData, err := e.prepareView(name, data, rs)
+	if err != nil {
+		return err
+	}
+	e.setNonce(header, rs)
+	buf := new(bytes.Buffer)
+	if err := e.executeRender(buf, name, viewData, master, rs); err != nil {
+		return err
+	}
+
+	e.viewHeaders(header, rs)
+	conditional := statusCode == http.StatusOK && (r.Method == http.MethodGet || r.Method == http.MethodHead)
+
+	var etag string
+	if e.config.ETag {
+		sum := sha256.Sum256(buf.Bytes())
+		etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+		header.Set("ETag", etag)
+	}
+	var modTime time.Time
+	if e.config.LastModified {
+		modTime = lastModified(dataModTime(data), rs)
+		if !modTime.IsZero() {
+			modTime = modTime.UTC().Truncate(time.Second)
+			header.Set("Last-Modified", modTime.Format(http.TimeFormat))
+		}
+	}
+
+	if conditional && notModified(r, etag, modTime) {
+		delete(header, "Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	header.Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(statusCode)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// notModified reports whether the conditional request headers match, If-None-Match
+// takes precedence over If-Modified-Since.
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagMatch(inm, etag)
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modTime.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !modTime.After(t)
+	}
+	return false
+}
+
+// etagMatch uses the weak comparison of If-None-Match.
+func etagMatch(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// lastModified is the latest of the data time and the template times, zero when a
+// template time is unknown.
+func lastModified(dataTime time.Time, rs *renderState) time.Time {
+	if rs.unknownTime {
+		return time.Time{}
+	}
+	if dataTime.After(rs.modTime) {
+		return dataTime
+	}
+	return rs.modTime
+}
+
+func dataModTime(data interface{}) time.Time {
+	switch d := data.(type) {
+	case LastModifier:
+		return d.LastModified()
+	case M:
+		t, _ := d[LastModifiedKey].(time.Time)
+		return t
+	case map[string]interface{}:
+		t, _ := d[LastModifiedKey].(time.Time)
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,134 @@
+package goview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRenderRequestETag(t *testing.T) {
+	e := newTestEngine(Config{Extension: ".html", ETag: true}, map[string]string{
+		"index.html": `<p>{{.title}}</p>`,
+	})
+	data := M{"title": "hello"}
+
+	rec := httptest.NewRecorder()
+	if err := e.RenderRequest(rec, httptest.NewRequest("GET", "/", nil), http.StatusOK, "index.html", data); err != nil {
+		t.Fatal(err)
+	}
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Body.String() != "<p>hello</p>" || rec.Header().Get("Content-Length") != "12" {
+		t.Fatalf("unexpected response: %d %q %q", rec.Code, etag, rec.Body.String())
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	rec = httptest.NewRecorder()
+	if err := e.RenderRequest(rec, req, http.StatusOK, "index.html", data); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected 304 without body, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	if err := e.RenderRequest(rec, req, http.StatusOK, "index.html", M{"title": "changed"}); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || rec.Body.String() != "<p>changed</p>" {
+		t.Errorf("expected 200 for changed content, got %d", rec.Code)
+	}
+}
+
+// newFSTestEngine reads the files from a fstest.MapFS, with their modification times.
+func newFSTestEngine(config Config, files fstest.MapFS) *ViewEngine {
+	e := New(config)
+	e.SetFileHandler(FSFileHandler(files))
+	e.SetModTimeHandler(FSModTimeHandler(files))
+	return e
+}
+
+func TestRenderRequestLastModified(t *testing.T) {
+	e := newFSTestEngine(Config{Extension: ".html", LastModified: true}, fstest.MapFS{
+		"index.html": {Data: []byte(`{{.title}}`), ModTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	})
+	updated := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	data := M{"title": "hello", LastModifiedKey: updated}
+
+	rec := httptest.NewRecorder()
+	if err := e.RenderRequest(rec, httptest.NewRequest("GET", "/", nil), http.StatusOK, "index.html", data); err != nil {
+		t.Fatal(err)
+	}
+	if lm := rec.Header().Get("Last-Modified"); lm != updated.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q", lm)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-Modified-Since", updated.Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	if err := e.RenderRequest(rec, req, http.StatusOK, "index.html", data); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("code = %d, want 304", rec.Code)
+	}
+
+	req.Header.Set("If-Modified-Since", updated.Add(-time.Minute).Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	if err := e.RenderRequest(rec, req, http.StatusOK, "index.html", data); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("code = %d, want 200", rec.Code)
+	}
+}
+
+func TestRenderRequestLastModifiedTemplates(t *testing.T) {
+	view := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	partial := view.Add(time.Hour)
+	e := newFSTestEngine(Config{
+		Extension:    ".html",
+		Master:       "layouts/master",
+		Partials:     []string{"partials/ad"},
+		LastModified: true,
+	}, fstest.MapFS{
+		"layouts/master.html": {Data: []byte(`{{template "content" .}}`), ModTime: view},
+		"partials/ad.html":    {Data: []byte(`ad`), ModTime: partial},
+		"index.html":          {Data: []byte(`{{define "content"}}{{.title}} {{template "partials/ad" .}}{{end}}`), ModTime: view},
+	})
+
+	// The data is older than the partial, the partial time is used.
+	rec := httptest.NewRecorder()
+	data := M{"title": "hello", LastModifiedKey: view.Add(time.Minute)}
+	if err := e.RenderRequest(rec, httptest.NewRequest("GET", "/", nil), http.StatusOK, "index", data); err != nil {
+		t.Fatal(err)
+	}
+	if lm := rec.Header().Get("Last-Modified"); lm != partial.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q, want %q", lm, partial.Format(http.TimeFormat))
+	}
+
+	rec = httptest.NewRecorder()
+	if err := e.RenderRequest(rec, httptest.NewRequest("GET", "/", nil), http.StatusOK, "index", M{"title": "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if lm := rec.Header().Get("Last-Modified"); lm != partial.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q without a data time, want %q", lm, partial.Format(http.TimeFormat))
+	}
+}
+
+func TestRenderRequestLastModifiedUnknown(t *testing.T) {
+	// The file handler of newTestEngine has no modification times.
+	e := newTestEngine(Config{Extension: ".html", LastModified: true}, map[string]string{
+		"index.html": `{{.title}}`,
+	})
+	rec := httptest.NewRecorder()
+	data := M{"title": "hello", LastModifiedKey: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := e.RenderRequest(rec, httptest.NewRequest("GET", "/", nil), http.StatusOK, "index.html", data); err != nil {
+		t.Fatal(err)
+	}
+	if lm, ok := rec.Header()["Last-Modified"]; ok {
+		t.Errorf("Last-Modified = %q with unknown template times", lm)
+	}
+}
@@ -0,0 +1,25 @@
+package goview
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadConfig reads a YAML or JSON config file with the keys of the Config yaml tags, missing
+// keys keep the values of DefaultConfig. Unknown keys are errors. Paths such as Root are kept
+// as written, relative to the working directory; the goview command resolves them against the file.
+func LoadConfig(file string) (Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Config{}, err
+	}
+	config := DefaultConfig
+	config.Funcs = make(template.FuncMap)
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return Config{}, fmt.Errorf("goview: config %s: %v", file, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,30 @@
+package goview
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "goview.yaml")
+	os.WriteFile(file, []byte("root: templates\nextensions: [.html, .tmpl]\npartials: [partials/head]\nminify: true\n"), 0644)
+
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Root != "templates" || len(config.Extensions) != 2 || config.Partials[0] != "partials/head" || !config.Minify {
+		t.Errorf("unexpected config %+v", config)
+	}
+	if config.Master != DefaultConfig.Master || config.Extension != ".html" || config.Delims.Left != "{{" {
+		t.Errorf("defaults not kept %+v", config)
+	}
+
+	os.WriteFile(file, []byte(`{"root": "views", "extention": ".tpl"}`), 0644)
+	if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "extention") {
+		t.Errorf("expected error of unknown key, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package goview
+
+import (
+	"mime"
+	"path"
+	"strings"
+)
+
+// ExtensionTypes maps a view extension to its Content-Type, such as `feed.xml` or `robots.txt`.
+// Views of HTML, XML, JSON and JavaScript types are escaped by html/template, others by text/template.
+var ExtensionTypes = map[string]string{
+	".html":  "text/html; charset=utf-8",
+	".htm":   "text/html; charset=utf-8",
+	".xhtml": "application/xhtml+xml; charset=utf-8",
+	".xml":   "application/xml; charset=utf-8",
+	".rss":   "application/rss+xml; charset=utf-8",
+	".atom":  "application/atom+xml; charset=utf-8",
+	".svg":   "image/svg+xml",
+	".txt":   "text/plain; charset=utf-8",
+	".md":    "text/markdown; charset=utf-8",
+	".csv":   "text/csv; charset=utf-8",
+	".json":  "application/json; charset=utf-8",
+	".yaml":  "application/yaml; charset=utf-8",
+	".yml":   "application/yaml; charset=utf-8",
+	".js":    "text/javascript; charset=utf-8",
+	".css":   "text/css; charset=utf-8",
+	".ics":   "text/calendar; charset=utf-8",
+}
+
+// ContentType returns the Content-Type of a view. It is declared by Config.ContentTypes or the
+// `contenttype` front matter, or inferred from a secondary extension of the name, such as `feed.xml`
+// or `feed.xml.html`, then from the template extension, and defaults to HTMLContentType,
+// or TextContentType for NewText engines.
+func (e *ViewEngine) ContentType(name string) string {
+	meta, err := e.meta(name)
+	if err != nil {
+		meta = new(Meta)
+	}
+	contentType, _ := e.viewType(name, meta)
+	return contentType
+}
+
+// viewType returns the Content-Type of a view with its front matter and whether it is rendered
+// with text/template.
+func (e *ViewEngine) viewType(view string, meta *Meta) (contentType string, text bool) {
+	name, _, _ := e.splitExtension(view)
+	contentType = e.config.ContentTypes[name]
+	if contentType == "" {
+		contentType = meta.ContentType
+	}
+	if contentType == "" {
+		contentType = e.inferredType(view)
+	}
+	return contentType, e.text || !escapesHTML(contentType)
+}
+
+// inferredType returns the Content-Type of a view from Config.ContentTypes and its name,
+// without its front matter.
+func (e *ViewEngine) inferredType(view string) string {
+	name, ext, _ := e.splitExtension(view)
+	contentType := e.config.ContentTypes[name]
+	if contentType == "" && e.isMarkdown(name) {
+		contentType = HTMLContentType[0]
+	}
+	if contentType == "" {
+		contentType = ExtensionTypes[strings.ToLower(path.Ext(name))]
+	}
+	if contentType == "" {
+		contentType = ExtensionTypes[strings.ToLower(ext)]
+	}
+	if contentType == "" {
+		contentType = e.contentType()[0]
+	}
+	return contentType
+}
+
+// escapesHTML reports whether html/template escaping suits the content type, HTML and XML only.
+// JSON and JavaScript views render with text/template and escape values with json and js.
+func escapesHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "text/html", "application/xml", "text/xml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+xml")
+}
+
+// isHTML reports whether the content type is text/html.
+func isHTML(contentType string) bool {
+	return mediaType(contentType) == "text/html"
+}
+
+// mediaType returns the media type of a Content-Type without its parameters.
+func mediaType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType
+}
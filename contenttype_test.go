@@ -0,0 +1,53 @@
+package goview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContentType(t *testing.T) {
+	e := newTestEngine(Config{
+		Extension:    ".html",
+		Master:       "layouts/master",
+		ContentTypes: map[string]string{"sitemap": "application/xml"},
+	}, map[string]string{
+		"layouts/master.html": `<main>{{template "content" .}}</main>`,
+		"index.html":          `{{define "content"}}{{.title}}{{end}}`,
+		"feed.xml.html":       `<title>{{.title}}</title>`,
+		"robots.txt.html":     `# {{.title}}`,
+		"sitemap.html":        `<loc>{{.title}}</loc>`,
+		"data.json.html":      `{"title": "{{.title}}"}`,
+		"app.js.html":         `var title = "{{.title}}";`,
+		"api.json.html":       `{"title": {{json .title}}}`,
+		"escape.js.html":      `var title = "{{js .title}}";`,
+	})
+
+	for _, tt := range []struct{ name, contentType, body string }{
+		{"index", "text/html; charset=utf-8", `<main>A &amp; &lt;B&gt;</main>`},
+		{"index.html", "text/html; charset=utf-8", ""},
+		{"feed.xml.html", "application/xml; charset=utf-8", `<title>A &amp; &lt;B&gt;</title>`},
+		{"robots.txt.html", "text/plain; charset=utf-8", `# A & <B>`},
+		{"sitemap.html", "application/xml", `<loc>A &amp; &lt;B&gt;</loc>`},
+		// views of another type than text/html render without master
+		{"feed.xml", "application/xml; charset=utf-8", `<title>A &amp; &lt;B&gt;</title>`},
+		{"sitemap", "application/xml", `<loc>A &amp; &lt;B&gt;</loc>`},
+		{"robots.txt", "text/plain; charset=utf-8", `# A & <B>`},
+		// JSON and JavaScript views render with text/template, values are escaped with json and js
+		{"data.json", "application/json; charset=utf-8", `{"title": "A & <B>"}`},
+		{"app.js", "text/javascript; charset=utf-8", `var title = "A & <B>";`},
+		{"api.json", "application/json; charset=utf-8", `{"title": "A \u0026 \u003cB\u003e"}`},
+		{"escape.js", "text/javascript; charset=utf-8", `var title = "A \u0026 \u003CB\u003E";`},
+	} {
+		rec := httptest.NewRecorder()
+		if err := e.Render(rec, http.StatusOK, tt.name, M{"title": "A & <B>"}); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.name, ct, tt.contentType)
+		}
+		if rec.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.name, rec.Body.String(), tt.body)
+		}
+	}
+}
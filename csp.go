@@ -0,0 +1,52 @@
+package goview
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NoncePlaceholder is replaced by the per render nonce in Config.CSP.
+const NoncePlaceholder = "{nonce}"
+
+// renderState holds the values bound to one render, such as the CSP nonce.
+type renderState struct {
+	nonce       string
+	view        string    //name of the rendered view
+	meta        *Meta     //front matter of the rendered view
+	contentType string    //Content-Type of the rendered view
+	text        bool      //rendered with text/template, see ContentType
+	modTime     time.Time //latest modification time of the templates used
+	unknownTime bool      //a template has no known modification time
+}
+
+// touch records the modification time of a template used by the render, zero when unknown.
+func (rs *renderState) touch(t time.Time) {
+	if t.IsZero() {
+		rs.unknownTime = true
+	} else if t.After(rs.modTime) {
+		rs.modTime = t
+	}
+}
+
+// setNonce sets the nonce of a render and the Content-Security-Policy header when Config.CSP
+// is configured.
+func (e *ViewEngine) setNonce(header http.Header, rs *renderState) {
+	if e.config.CSP == "" {
+		return
+	}
+	rs.nonce = NewNonce()
+	header.Set("Content-Security-Policy", strings.Replace(e.config.CSP, NoncePlaceholder, rs.nonce, -1))
+}
+
+// NewNonce returns a random url safe base64 nonce for a Content-Security-Policy,
+// it needs no escaping in html attributes.
+func NewNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("goview: crypto/rand failed: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,60 @@
+package goview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCSPNonce(t *testing.T) {
+	e := newTestEngine(Config{
+		Extension: ".html",
+		CSP:       "script-src 'nonce-{nonce}'",
+	}, map[string]string{
+		"index.html":   `<script nonce="{{cspNonce}}">var a = 1;</script>{{include "partial"}}`,
+		"partial.html": `<style nonce="{{cspNonce}}"></style>`,
+	})
+
+	seen := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		if err := e.Render(rec, http.StatusOK, "index.html", nil); err != nil {
+			t.Fatal(err)
+		}
+		policy := rec.Header().Get("Content-Security-Policy")
+		nonce := strings.TrimSuffix(strings.TrimPrefix(policy, "script-src 'nonce-"), "'")
+		if nonce == "" || nonce == policy || seen[nonce] {
+			t.Fatalf("unexpected policy %q", policy)
+		}
+		seen[nonce] = true
+		want := `<script nonce="` + nonce + `">var a = 1;</script><style nonce="` + nonce + `"></style>`
+		if got := rec.Body.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestIncludeConcurrentData(t *testing.T) {
+	e := newTestEngine(Config{Extension: ".html"}, map[string]string{
+		"index.html":   `{{include "partial"}}`,
+		"partial.html": `{{.}}`,
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(v string) {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			if err := e.Render(rec, http.StatusOK, "index.html", v); err != nil {
+				t.Error(err)
+				return
+			}
+			if rec.Body.String() != v {
+				t.Errorf("include rendered %q, want %q", rec.Body.String(), v)
+			}
+		}(strings.Repeat("x", i))
+	}
+	wg.Wait()
+}
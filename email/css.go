@@ -0,0 +1,295 @@
+package email
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// InlineCSS moves the rules of `<style>` blocks into the `style` attribute of the matching
+// elements, most mail clients ignore style sheets. Selectors made of type, class and id
+// selectors and the descendant combinator are inlined, such as `p`, `.btn`, `table td.total`.
+// Other rules, such as `a:hover` and `@media`, are kept in the `<style>` block.
+// Inline `style` attributes take precedence over inlined rules.
+func InlineCSS(doc *html.Node) error {
+	var styles []*html.Node
+	walk(doc, func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "style" {
+			styles = append(styles, n)
+		}
+	})
+
+	var rules []cssRule
+	for _, style := range styles {
+		css := nodeText(style)
+		inline, kept := parseCSS(css, len(rules))
+		rules = append(rules, inline...)
+		if kept == "" {
+			style.Parent.RemoveChild(style)
+			continue
+		}
+		for c := style.FirstChild; c != nil; c = style.FirstChild {
+			style.RemoveChild(c)
+		}
+		style.AppendChild(&html.Node{Type: html.TextNode, Data: kept})
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].specificity != rules[j].specificity {
+			return rules[i].specificity < rules[j].specificity
+		}
+		return rules[i].order < rules[j].order
+	})
+
+	walk(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+		var decls []cssDecl
+		for _, rule := range rules {
+			if rule.match(n) {
+				decls = append(decls, rule.decls...)
+			}
+		}
+		if len(decls) == 0 {
+			return
+		}
+		for i, a := range n.Attr {
+			if a.Key == "style" {
+				decls = append(decls, parseDecls(a.Val)...)
+				n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+				break
+			}
+		}
+		n.Attr = append(n.Attr, html.Attribute{Key: "style", Val: formatDecls(decls)})
+	})
+	return nil
+}
+
+type cssDecl struct {
+	property string
+	value    string
+}
+
+type cssRule struct {
+	selector    []cssCompound //compound selectors joined by descendant combinators
+	specificity int
+	order       int
+	decls       []cssDecl
+}
+
+type cssCompound struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+// parseCSS returns the inlinable rules of a style sheet and the css that must stay in a <style> block.
+func parseCSS(css string, order int) (rules []cssRule, kept string) {
+	css = stripComments(css)
+	var keep strings.Builder
+	for {
+		css = strings.TrimSpace(css)
+		open := strings.IndexByte(css, '{')
+		if open < 0 {
+			break
+		}
+		prelude := strings.TrimSpace(css[:open])
+		end := blockEnd(css, open)
+		body := css[open+1 : end]
+		block := css[:end+1]
+		if end+1 < len(css) {
+			css = css[end+1:]
+		} else {
+			css = ""
+		}
+
+		if strings.HasPrefix(prelude, "@") {
+			keep.WriteString(block + "\n")
+			continue
+		}
+		decls := parseDecls(body)
+		var unsupported []string
+		for _, sel := range strings.Split(prelude, ",") {
+			sel = strings.TrimSpace(sel)
+			compounds, specificity, ok := parseSelector(sel)
+			if !ok {
+				unsupported = append(unsupported, sel)
+				continue
+			}
+			rules = append(rules, cssRule{selector: compounds, specificity: specificity, order: order, decls: decls})
+			order++
+		}
+		if len(unsupported) > 0 {
+			keep.WriteString(strings.Join(unsupported, ", ") + " {" + body + "}\n")
+		}
+	}
+	return rules, strings.TrimSpace(keep.String())
+}
+
+// blockEnd returns the index of the brace closing the block opened at open.
+func blockEnd(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); i++ {
+		switch css[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(css) - 1
+}
+
+func stripComments(css string) string {
+	for {
+		start := strings.Index(css, "/*")
+		if start < 0 {
+			return css
+		}
+		end := strings.Index(css[start+2:], "*/")
+		if end < 0 {
+			return css[:start]
+		}
+		css = css[:start] + css[start+2+end+2:]
+	}
+}
+
+func parseSelector(sel string) ([]cssCompound, int, bool) {
+	fields := strings.Fields(sel)
+	if len(fields) == 0 {
+		return nil, 0, false
+	}
+	compounds := make([]cssCompound, 0, len(fields))
+	specificity := 0
+	for _, field := range fields {
+		var c cssCompound
+		for field != "" {
+			kind := byte(0)
+			if field[0] == '.' || field[0] == '#' {
+				kind = field[0]
+				field = field[1:]
+			}
+			n := 0
+			for n < len(field) && isIdentChar(field[n]) {
+				n++
+			}
+			name := field[:n]
+			field = field[n:]
+			switch {
+			case kind == 0 && name == "" && strings.HasPrefix(field, "*"):
+				field = field[1:]
+			case name == "":
+				return nil, 0, false
+			case kind == '.':
+				c.classes = append(c.classes, name)
+				specificity += 10
+			case kind == '#':
+				c.id = name
+				specificity += 100
+			default:
+				c.tag = strings.ToLower(name)
+				specificity++
+			}
+		}
+		compounds = append(compounds, c)
+	}
+	return compounds, specificity, true
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+func (r cssRule) match(n *html.Node) bool {
+	last := len(r.selector) - 1
+	if !r.selector[last].match(n) {
+		return false
+	}
+	i := last - 1
+	for p := n.Parent; p != nil && i >= 0; p = p.Parent {
+		if p.Type == html.ElementNode && r.selector[i].match(p) {
+			i--
+		}
+	}
+	return i < 0
+}
+
+func (c cssCompound) match(n *html.Node) bool {
+	if c.tag != "" && c.tag != n.Data {
+		return false
+	}
+	if c.id != "" && attr(n, "id") != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(attr(n, "class"))
+		for _, want := range c.classes {
+			if indexOf(classes, want) < 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func parseDecls(body string) []cssDecl {
+	var decls []cssDecl
+	for _, d := range strings.Split(body, ";") {
+		i := strings.IndexByte(d, ':')
+		if i < 0 {
+			continue
+		}
+		property := strings.ToLower(strings.TrimSpace(d[:i]))
+		value := strings.TrimSpace(d[i+1:])
+		if property != "" && value != "" {
+			decls = append(decls, cssDecl{property, value})
+		}
+	}
+	return decls
+}
+
+// formatDecls joins declarations, a later declaration of a property replaces an earlier one
+// unless the earlier is `!important`.
+func formatDecls(decls []cssDecl) string {
+	var order []string
+	values := make(map[string]string)
+	for _, d := range decls {
+		prev, ok := values[d.property]
+		if !ok {
+			order = append(order, d.property)
+		} else if strings.HasSuffix(prev, "!important") && !strings.HasSuffix(d.value, "!important") {
+			continue
+		}
+		values[d.property] = d.value
+	}
+	parts := make([]string, len(order))
+	for i, property := range order {
+		parts[i] = property + ": " + values[property]
+	}
+	return strings.Join(parts, "; ")
+}
+
+func walk(n *html.Node, fn func(*html.Node)) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		walk(c, fn)
+		c = next
+	}
+	fn(n)
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
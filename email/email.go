@@ -0,0 +1,129 @@
+/*
+Package email renders transactional emails with a goview.ViewEngine.
+
+A message is the pair of views `welcome` (HTML, with master layout) and `welcome.txt`
+(plain text, without master). The text part is derived from the HTML when `welcome.txt`
+does not exist. CSS of `<style>` blocks is inlined into `style` attributes and the subject
+is taken from the `<title>` of the HTML.
+
+Example:
+
+	gv := goview.New(goview.Config{
+		Root:       "mails",
+		Extensions: []string{".html", ".txt"},
+		Master:     "layouts/master",
+	})
+	mails := email.New(gv)
+
+	msg, err := mails.Render("welcome", goview.M{"name": "Tom"})
+	if err != nil {
+		return err
+	}
+	msg.From = "shop@example.com"
+	msg.To = []string{"tom@example.com"}
+	return smtp.SendMail(addr, auth, msg.From, msg.Recipients(), msg.Bytes())
+*/
+package email
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/go-tea/goview"
+	"golang.org/x/net/html"
+)
+
+// TextSuffix is appended to the view name to find the plain-text part.
+const TextSuffix = ".txt"
+
+// Renderer renders messages with a goview.ViewEngine.
+type Renderer struct {
+	engine *goview.ViewEngine
+}
+
+// New function
+func New(engine *goview.ViewEngine) *Renderer {
+	return &Renderer{engine: engine}
+}
+
+// Render renders the HTML view name and the plain-text view `name.txt`. As with ViewEngine.Render,
+// `welcome` uses the master layout and `welcome.html` does not.
+// When the engine accepts the `.txt` extension the text view is the file `name.txt`,
+// otherwise `name.txt` with the engine extension, such as `welcome.txt.html`.
+func (r *Renderer) Render(name string, data interface{}) (*Message, error) {
+	buf := new(bytes.Buffer)
+	if err := r.engine.RenderWriter(buf, name, data); err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{Subject: title(doc)}
+	if err := InlineCSS(doc); err != nil {
+		return nil, err
+	}
+	out := new(bytes.Buffer)
+	if err := html.Render(out, doc); err != nil {
+		return nil, err
+	}
+	msg.HTML = out.String()
+
+	// only a missing text view falls back, errors rendering it, such as a missing include, do not
+	textName := r.textName(name)
+	if _, err := r.engine.Meta(textName); errors.Is(err, goview.ErrTemplateNotFound) {
+		msg.Text = Text(doc)
+		return msg, nil
+	} else if err != nil {
+		return nil, err
+	}
+	buf.Reset()
+	if err := r.engine.RenderWriter(buf, textName, data); err != nil {
+		return nil, err
+	}
+	msg.Text = buf.String()
+	return msg, nil
+}
+
+// textName returns the render name of the plain-text view of the HTML view name,
+// it never uses the master layout.
+func (r *Renderer) textName(name string) string {
+	config := r.engine.Config()
+	exts := config.Extensions
+	if len(exts) == 0 {
+		exts = []string{config.Extension}
+	}
+	for _, ext := range exts {
+		if ext != "" && path.Ext(name) == ext {
+			name = strings.TrimSuffix(name, ext)
+			break
+		}
+	}
+	for _, ext := range exts {
+		if ext == TextSuffix {
+			return name + TextSuffix
+		}
+	}
+	return name + TextSuffix + exts[0]
+}
+
+// title returns the text of the first <title> element.
+func title(n *html.Node) string {
+	if n.Type == html.ElementNode && n.Data == "title" {
+		var b strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.TextNode {
+				b.WriteString(c.Data)
+			}
+		}
+		return strings.Join(strings.Fields(b.String()), " ")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if t := title(c); t != "" {
+			return t
+		}
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+package email
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-tea/goview"
+)
+
+func newTestEngine(config goview.Config, files fstest.MapFS) *goview.ViewEngine {
+	e := goview.New(config)
+	e.SetFileHandler(goview.FSFileHandler(files))
+	return e
+}
+
+var master = `<html><head><title>{{.title}}</title><style>
+p { color: #333; margin: 0 }
+.btn { background: blue; color: white }
+a:hover { color: red }
+@media (max-width: 600px) { p { margin: 4px } }
+</style></head><body>{{template "content" .}}</body></html>`
+
+func TestRender(t *testing.T) {
+	e := newTestEngine(goview.Config{Extensions: []string{".html", ".txt"}, Master: "layouts/master"}, fstest.MapFS{
+		"layouts/master.html": {Data: []byte(master)},
+		"welcome.html":        {Data: []byte(`{{define "content"}}<p>Hello {{.name}}</p><a class="btn" style="color: black" href="https://example.com/start">Start</a>{{end}}`)},
+		"welcome.txt":         {Data: []byte(`Hello {{.name}}, start at https://example.com/start`)},
+	})
+
+	msg, err := New(e).Render("welcome", goview.M{"title": "Welcome Tom & Jerry", "name": "Tom & Jerry"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "Welcome Tom & Jerry" {
+		t.Errorf("Subject = %q", msg.Subject)
+	}
+	if msg.Text != "Hello Tom & Jerry, start at https://example.com/start" {
+		t.Errorf("Text = %q", msg.Text)
+	}
+	for _, want := range []string{
+		`<p style="color: #333; margin: 0">Hello Tom &amp; Jerry</p>`,
+		`<a class="btn" href="https://example.com/start" style="background: blue; color: black">Start</a>`,
+		"a:hover {", "@media (max-width: 600px)",
+	} {
+		if !strings.Contains(msg.HTML, want) {
+			t.Errorf("HTML %q does not contain %q", msg.HTML, want)
+		}
+	}
+}
+
+func TestRenderDerivedText(t *testing.T) {
+	e := newTestEngine(goview.Config{Extension: ".html"}, fstest.MapFS{
+		"welcome.html": {Data: []byte(`<html><head><title>Hi</title></head><body><h1>Hello {{.}}</h1>
+<p>Your   account is
+ready.</p><ul><li>One</li><li>Two</li></ul><p><a href="https://example.com">Log in</a></p></body></html>`)},
+	})
+
+	msg, err := New(e).Render("welcome.html", "Tom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Hello Tom\n\nYour account is ready.\n\n- One\n- Two\n\nLog in (https://example.com)\n"
+	if msg.Text != want {
+		t.Errorf("Text = %q, want %q", msg.Text, want)
+	}
+
+	e = newTestEngine(goview.Config{Extension: ".html"}, fstest.MapFS{
+		"welcome.html": {Data: []byte(`{{include "missing"}}`)},
+	})
+	if _, err := New(e).Render("welcome.html", nil); err == nil {
+		t.Error("expected error of missing include")
+	}
+
+	// a text view including a missing partial is an error, not a derived text
+	e = newTestEngine(goview.Config{Extensions: []string{".html", ".txt"}}, fstest.MapFS{
+		"welcome.html": {Data: []byte(`<p>Hello</p>`)},
+		"welcome.txt":  {Data: []byte(`Hello {{include "missing.txt"}}`)},
+	})
+	if _, err := New(e).Render("welcome.html", nil); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("err = %v, want the missing include of welcome.txt", err)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	msg := &Message{
+		From:    "shop@example.com",
+		To:      []string{"tom@example.com", "jerry@example.com"},
+		Bcc:     []string{"audit@example.com"},
+		Subject: "Grüße\r\nBcc: evil@example.com",
+		Header:  map[string]string{"reply-to": "help@example.com"},
+		Text:    "Hello\nTom\r\nand Jerry",
+		HTML:    `<p style="color: red">Hello</p>`,
+	}
+	m, err := mail.ReadMessage(strings.NewReader(string(msg.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Header.Get("Bcc") != "" || m.Header.Get("Reply-To") != "help@example.com" || m.Header.Get("To") != "tom@example.com, jerry@example.com" {
+		t.Errorf("unexpected header %v", m.Header)
+	}
+	if rcpt := msg.Recipients(); fmt.Sprint(rcpt) != "[tom@example.com jerry@example.com audit@example.com]" {
+		t.Errorf("Recipients = %q", rcpt)
+	}
+	if strings.Contains(string(msg.Bytes()), "audit@example.com") {
+		t.Error("Bcc recipient written in the message")
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if subject != "Grüße  Bcc: evil@example.com" {
+		t.Errorf("Subject = %q", subject)
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", mediaType)
+	}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	var parts []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(p)
+		parts = append(parts, fmt.Sprintf("%s|%s", p.Header.Get("Content-Type"), body))
+	}
+	want := []string{"text/plain; charset=utf-8|Hello\r\nTom\r\nand Jerry", `text/html; charset=utf-8|<p style="color: red">Hello</p>`}
+	if fmt.Sprint(parts) != fmt.Sprint(want) {
+		t.Errorf("parts = %q, want %q", parts, want)
+	}
+}
+
+func TestTextName(t *testing.T) {
+	for _, tt := range []struct {
+		config     goview.Config
+		name, want string
+	}{
+		{goview.Config{Extension: ".html"}, "welcome", "welcome.txt.html"},
+		{goview.Config{Extension: ".html"}, "welcome.html", "welcome.txt.html"},
+		{goview.Config{Extensions: []string{".tmpl", ".txt"}}, "welcome", "welcome.txt"},
+		{goview.Config{Extensions: []string{".tmpl", ".txt"}}, "mails/welcome.tmpl", "mails/welcome.txt"},
+	} {
+		if got := New(goview.New(tt.config)).textName(tt.name); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
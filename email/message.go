@@ -0,0 +1,102 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"mime"
+	"mime/quotedprintable"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is a rendered email, fields can be inspected or changed before Bytes.
+type Message struct {
+	From    string
+	To      []string
+	Cc      []string
+	Bcc     []string //envelope recipients only, never written in the message
+	Subject string
+	Date    time.Time         //Date header, omitted when zero
+	Header  map[string]string //additional headers, such as Reply-To
+	Text    string            //text/plain part
+	HTML    string            //text/html part with inlined CSS
+}
+
+// Bytes returns the message as MIME multipart/alternative with quoted-printable text and HTML parts,
+// ready for smtp.SendMail. Bcc recipients are only given to the mail server with Recipients,
+// never in the message.
+func (m *Message) Bytes() []byte {
+	buf := new(bytes.Buffer)
+	boundary := newBoundary()
+
+	header := make(map[string]string, len(m.Header)+6)
+	for k, v := range m.Header {
+		header[textproto.CanonicalMIMEHeaderKey(k)] = v
+	}
+	header["Mime-Version"] = "1.0"
+	header["Content-Type"] = mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": boundary})
+	if m.From != "" {
+		header["From"] = m.From
+	}
+	if len(m.To) > 0 {
+		header["To"] = strings.Join(m.To, ", ")
+	}
+	if len(m.Cc) > 0 {
+		header["Cc"] = strings.Join(m.Cc, ", ")
+	}
+	if m.Subject != "" {
+		header["Subject"] = mime.QEncoding.Encode("utf-8", sanitizeHeader(m.Subject))
+	}
+	if !m.Date.IsZero() {
+		header["Date"] = m.Date.Format(time.RFC1123Z)
+	}
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteString(k + ": " + sanitizeHeader(header[k]) + "\r\n")
+	}
+	buf.WriteString("\r\n")
+
+	writePart(buf, boundary, "text/plain; charset=utf-8", m.Text)
+	writePart(buf, boundary, "text/html; charset=utf-8", m.HTML)
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes()
+}
+
+// Recipients returns the envelope recipients To, Cc and Bcc, for smtp.SendMail.
+func (m *Message) Recipients() []string {
+	rcpt := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	rcpt = append(rcpt, m.To...)
+	rcpt = append(rcpt, m.Cc...)
+	return append(rcpt, m.Bcc...)
+}
+
+func writePart(buf *bytes.Buffer, boundary, contentType, body string) {
+	buf.WriteString("--" + boundary + "\r\n")
+	buf.WriteString("Content-Type: " + contentType + "\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(buf)
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	qp.Close()
+	buf.WriteString("\r\n")
+}
+
+func newBoundary() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("email: crypto/rand failed: " + err.Error())
+	}
+	return "goview-" + hex.EncodeToString(b)
+}
+
+// sanitizeHeader prevents header injection through line breaks in header values.
+func sanitizeHeader(v string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+}
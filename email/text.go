@@ -0,0 +1,159 @@
+package email
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockElements start on a new line in the plain-text part.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "div": true,
+	"dl": true, "dt": true, "dd": true, "footer": true, "form": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "tr": true, "ul": true,
+}
+
+// Text derives a plain-text version of an HTML document, links are written as `text (href)`.
+func Text(doc *html.Node) string {
+	t := &textWriter{}
+	t.node(doc)
+	lines := strings.Split(t.b.String(), "\n")
+	out := make([]string, 0, len(lines))
+	blank := true
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		out = append(out, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(out, "\n")) + "\n"
+}
+
+type textWriter struct {
+	b    strings.Builder
+	last byte
+	pre  int
+}
+
+func (t *textWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	t.b.WriteString(s)
+	t.last = s[len(s)-1]
+}
+
+// newline ends the current line, if any.
+func (t *textWriter) newline() {
+	if t.last != 0 && t.last != '\n' {
+		t.write("\n")
+	}
+}
+
+// space writes a single space between words of the same line.
+func (t *textWriter) space() {
+	if t.last != 0 && t.last != ' ' && t.last != '\n' {
+		t.write(" ")
+	}
+}
+
+func (t *textWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if t.pre > 0 {
+			t.write(n.Data)
+			return
+		}
+		words := strings.Fields(n.Data)
+		if n.Data != "" && isSpace(n.Data[0]) {
+			t.space()
+		}
+		t.write(strings.Join(words, " "))
+		if len(words) > 0 && isSpace(n.Data[len(n.Data)-1]) {
+			t.space()
+		}
+		return
+	case html.ElementNode:
+		switch n.Data {
+		case "head", "script", "style", "title":
+			return
+		case "br":
+			t.write("\n")
+			return
+		}
+	}
+
+	element := ""
+	if n.Type == html.ElementNode {
+		element = n.Data
+	}
+	block := blockElements[element]
+	paragraph := element == "p" || len(element) == 2 && element[0] == 'h' && element[1] >= '1' && element[1] <= '6'
+	if block {
+		t.newline()
+		if paragraph {
+			t.write("\n")
+		}
+		if element == "li" {
+			t.write("- ")
+		}
+	}
+	if element == "pre" {
+		t.pre++
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		t.node(c)
+	}
+	switch element {
+	case "pre":
+		t.pre--
+	case "a":
+		if href := attr(n, "href"); href != "" && !strings.HasPrefix(href, "#") && href != nodeText(n) {
+			t.write(" (" + href + ")")
+		}
+	case "td", "th":
+		t.space()
+	}
+	if block {
+		t.newline()
+		if paragraph {
+			t.write("\n")
+		}
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t' || c == '\r' || c == '\f'
+}
+
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(b.String())
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -1,5 +1,11 @@
 package goview
 
+import "errors"
+
+// ErrTemplateNotFound is matched by errors.Is when the file of a template does not exist,
+// it is reported by DefaultFileHandler, FSFileHandler and file handlers returning fs.ErrNotExist.
+var ErrTemplateNotFound = errors.New("goview: template not found")
+
 // Error allows StatusError to satisfy the error interface.
 func (se StatusError) Error() string {
 	return se.Err.Error()
@@ -10,6 +16,11 @@ func (se StatusError) Status() int {
 	return se.Code
 }
 
+// Unwrap returns the underlying error, for errors.Is and errors.As.
+func (se StatusError) Unwrap() error {
+	return se.Err
+}
+
 // IStatusError represents a handler error. It provides methods for a HTTP status
 // code and embeds the built-in error interface.
 type IStatusError interface {
@@ -0,0 +1,193 @@
+package goview
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// frontMatterDelim starts and ends a YAML front matter block.
+const frontMatterDelim = "---"
+
+// Meta is the front matter of an HTML or markdown view, a YAML block between `---` lines at the
+// start of the file. Views of other types, such as `config.yaml` or `robots.txt`, are left alone.
+// The front matter of masters and Config.Partials is ignored, an included view parses its own
+// `partials`.
+//
+//	---
+//	title: Pricing
+//	layout: layouts/landing
+//	partials: [partials/plans]
+//	cachettl: 10m
+//	author: Tom
+//	---
+type Meta struct {
+	Layout      *string                `yaml:"layout"`      //master layout, overrides Config.Master, `layout:` empty renders without layout
+	Title       string                 `yaml:"title"`       //page title
+	Partials    []string               `yaml:"partials"`    //partials parsed with the view, after Config.Partials
+	CacheTTL    time.Duration          `yaml:"cachettl"`    //Cache-Control max-age of the response, such as 10m
+	ContentType string                 `yaml:"contenttype"` //Content-Type of the view
+	Params      map[string]interface{} `yaml:",inline"`     //custom metadata
+}
+
+// Get returns a field by its yaml name, or a custom param.
+func (m *Meta) Get(key string) interface{} {
+	switch key {
+	case "layout":
+		if m.Layout == nil {
+			return nil
+		}
+		return *m.Layout
+	case "title":
+		return m.Title
+	case "partials":
+		return m.Partials
+	case "cachettl":
+		return m.CacheTTL
+	case "contenttype":
+		return m.ContentType
+	}
+	return m.Params[key]
+}
+
+// parseMeta parses a front matter block.
+func parseMeta(front string) (*Meta, error) {
+	meta := new(Meta)
+	if err := yaml.Unmarshal([]byte(front), meta); err != nil {
+		return nil, err
+	}
+	if meta.Layout == nil {
+		// `layout:` without value renders without layout
+		var keys map[string]interface{}
+		if err := yaml.Unmarshal([]byte(front), &keys); err != nil {
+			return nil, err
+		}
+		if _, ok := keys["layout"]; ok {
+			meta.Layout = new(string)
+		}
+	}
+	return meta, nil
+}
+
+// Meta returns the front matter of the view name, a view without front matter has an empty Meta.
+func (e *ViewEngine) Meta(name string) (Meta, error) {
+	meta, err := e.meta(name)
+	if err != nil {
+		return Meta{}, err
+	}
+	return *meta, nil
+}
+
+// meta returns the cached front matter of the view name.
+func (e *ViewEngine) meta(name string) (*Meta, error) {
+	e.tplMutex.RLock()
+	meta, ok := e.metaMap[name]
+	e.tplMutex.RUnlock()
+	if ok && !e.config.DisableCache {
+		return meta, nil
+	}
+
+	var err error
+	if e.isMarkdown(name) {
+		var md *markdownFile
+		if md, err = e.loadMarkdown(name); err != nil {
+			return nil, err
+		}
+		meta = md.meta
+	} else {
+		var src string
+		if src, err = e.readTemplate(name); err != nil {
+			return nil, fileHandlerError(err)
+		}
+		var front string
+		if e.hasFrontMatter(name) {
+			front, _, _ = splitFrontMatter(src)
+		}
+		if meta, err = parseMeta(front); err != nil {
+			se := new(StatusError)
+			se.Code = http.StatusInternalServerError
+			se.Err = fmt.Errorf("ViewEngine front matter name:%v, error: %v", name, err)
+			return nil, se
+		}
+	}
+
+	e.tplMutex.Lock()
+	e.metaMap[name] = meta
+	e.tplMutex.Unlock()
+	return meta, nil
+}
+
+// viewHeaders sets the Content-Type and the Cache-Control of the view prepared in rs, unless already set.
+func (e *ViewEngine) viewHeaders(header http.Header, rs *renderState) {
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = []string{rs.contentType}
+	}
+	if val := header["Cache-Control"]; len(val) == 0 && rs.meta.CacheTTL > 0 {
+		header.Set("Cache-Control", "max-age="+strconv.Itoa(int(rs.meta.CacheTTL/time.Second)))
+	}
+}
+
+// fileHandlerError wraps an error of the file handler, matching ErrTemplateNotFound when the file does not exist.
+func fileHandlerError(err error) error {
+	se := new(StatusError)
+	se.Code = http.StatusInternalServerError
+	se.Err = fmt.Errorf("ViewEngine fileHandler error: %w", err)
+	if errors.Is(err, fs.ErrNotExist) {
+		se.Err = fmt.Errorf("ViewEngine fileHandler error: %w: %w", ErrTemplateNotFound, err)
+	}
+	return se
+}
+
+// hasFrontMatter reports whether the view name may start with front matter, markdown views and
+// views of type text/html inferred from the name. A declared `contenttype` does not change it.
+func (e *ViewEngine) hasFrontMatter(name string) bool {
+	return e.isMarkdown(name) || isHTML(e.inferredType(name))
+}
+
+// splitFrontMatter splits the YAML front matter block at the start of src from the body.
+// ok is false for src without front matter.
+func splitFrontMatter(src string) (front, body string, ok bool) {
+	rest, ok := cutLine(src, frontMatterDelim)
+	if !ok {
+		return "", src, false
+	}
+	for i := 0; i < len(rest); {
+		end := strings.IndexByte(rest[i:], '\n')
+		line := rest[i:]
+		if end >= 0 {
+			line = rest[i : i+end+1]
+		}
+		if after, ok := cutLine(line, frontMatterDelim); ok && after == "" {
+			return rest[:i], rest[i+len(line):], true
+		}
+		if end < 0 {
+			break
+		}
+		i += end + 1
+	}
+	return "", src, false
+}
+
+// cutLine returns s after its first line when the line is exactly delim.
+func cutLine(s, delim string) (string, bool) {
+	if !strings.HasPrefix(s, delim) {
+		return s, false
+	}
+	rest := s[len(delim):]
+	rest = strings.TrimLeft(rest, " \t")
+	switch {
+	case rest == "":
+		return "", true
+	case strings.HasPrefix(rest, "\r\n"):
+		return rest[2:], true
+	case rest[0] == '\n':
+		return rest[1:], true
+	}
+	return s, false
+}
@@ -0,0 +1,128 @@
+package goview
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	for _, tt := range []struct {
+		src, front, body string
+		ok               bool
+	}{
+		{"---\r\ntitle: CRLF\r\n---\r\nbody", "title: CRLF\r\n", "body", true},
+		{"---\ntitle: EOF\n---", "title: EOF\n", "", true},
+		{"--- \ntitle: x\n", "", "--- \ntitle: x\n", false},
+		{"# no front matter\n---\n", "", "# no front matter\n---\n", false},
+	} {
+		front, body, ok := splitFrontMatter(tt.src)
+		if front != tt.front || body != tt.body || ok != tt.ok {
+			t.Errorf("%q: got %q, %q, %v", tt.src, front, body, ok)
+		}
+	}
+}
+
+func TestMeta(t *testing.T) {
+	e := newTestEngine(Config{Extension: ".html", Master: "layouts/master", Partials: []string{"partials/head"}}, map[string]string{
+		"layouts/master.html":  "---\ntitle: ignored\n---\n<title>{{meta \"title\"}}</title>{{template \"head\" .}}{{template \"content\" .}}",
+		"layouts/landing.html": `<h1>{{(meta).Title}} by {{meta "author"}}</h1>{{template "content" .}}{{template "plans"}}`,
+		"partials/head.html":   `{{define "head"}}<meta>{{end}}`,
+		"partials/plans.html":  `{{define "plans"}}<plans>{{end}}`,
+		"index.html":           "---\ntitle: Home\n---\n{{define \"content\"}}home{{end}}",
+		"pricing.html":         "---\ntitle: Pricing\nlayout: layouts/landing\npartials: [partials/plans]\ncachettl: 10m\nauthor: Tom\n---\n{{define \"content\"}}{{include \"plain\"}}{{end}}",
+		"plain.html":           "---\nlayout:\n---\nplain",
+		"feed.html":            "---\ncontenttype: application/atom+xml\n---\n<feed>{{.}}</feed>",
+	})
+
+	for _, tt := range []struct{ name, body, contentType, cacheControl string }{
+		{"index", "<title>Home</title><meta>home", "text/html; charset=utf-8", ""},
+		{"pricing", "<h1>Pricing by Tom</h1>plain<plans>", "text/html; charset=utf-8", "max-age=600"},
+		{"plain", "plain", "text/html; charset=utf-8", ""},
+		{"feed.html", "<feed>a &amp; b</feed>", "application/atom+xml", ""},
+	} {
+		rec := httptest.NewRecorder()
+		if err := e.Render(rec, http.StatusOK, tt.name, "a & b"); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if rec.Body.String() != tt.body || rec.Header().Get("Content-Type") != tt.contentType || rec.Header().Get("Cache-Control") != tt.cacheControl {
+			t.Errorf("%s: got %q %v", tt.name, rec.Body.String(), rec.Header())
+		}
+	}
+
+	meta, err := e.Meta("pricing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *meta.Layout != "layouts/landing" || meta.CacheTTL != 10*time.Minute || meta.Params["author"] != "Tom" || len(meta.Partials) != 1 {
+		t.Errorf("unexpected meta %+v", meta)
+	}
+	if meta, _ := e.Meta("index"); meta.Layout != nil || meta.Title != "Home" {
+		t.Errorf("unexpected meta %+v", meta)
+	}
+	if _, err := e.Meta("missing"); err == nil {
+		t.Error("expected error of missing view")
+	}
+}
+
+func TestFrontMatterViewTypes(t *testing.T) {
+	e := newTestEngine(Config{Extension: ".html", Master: "layouts/master"}, map[string]string{
+		// the front matter of masters is ignored
+		"layouts/master.html": "---\npartials: [partials/missing]\n---\n<main>{{template \"content\" .}}</main>",
+		"partials/star.html":  `{{define "star"}}*{{end}}`,
+		"index.html":          "---\ntitle: Home\n---\n{{define \"content\"}}{{meta \"title\"}} {{include \"widget\"}}{{end}}",
+		"widget.html":         "---\npartials: [partials/star]\n---\n{{template \"star\"}}",
+		// only HTML and markdown views have front matter
+		"config.yaml.html": "---\nname: {{.}}\n---\nother: 1\n",
+		"notes.txt.html":   "---\ntitle: kept\n---\nbody",
+	})
+
+	for _, tt := range []struct{ name, body, contentType string }{
+		{"index", "<main>Home *</main>", "text/html; charset=utf-8"},
+		{"config.yaml.html", "---\nname: a & b\n---\nother: 1\n", "application/yaml; charset=utf-8"},
+		{"notes.txt.html", "---\ntitle: kept\n---\nbody", "text/plain; charset=utf-8"},
+	} {
+		rec := httptest.NewRecorder()
+		if err := e.Render(rec, http.StatusOK, tt.name, "a & b"); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if rec.Body.String() != tt.body || rec.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%s: got %q %q, want %q %q", tt.name, rec.Body.String(), rec.Header().Get("Content-Type"), tt.body, tt.contentType)
+		}
+	}
+	if meta, _ := e.Meta("notes.txt.html"); meta.Title != "" {
+		t.Errorf("notes.txt.html: unexpected meta %+v", meta)
+	}
+}
+
+func TestMetaOncePerRender(t *testing.T) {
+	files := map[string]string{
+		"layouts/master.html": `<main>{{template "content" .}}</main>`,
+		"index.html":          "---\ntitle: Home\ncachettl: 1m\n---\n{{define \"content\"}}{{meta \"title\"}}{{end}}",
+	}
+	reads := make(map[string]int)
+	e := New(Config{Extension: ".html", Master: "layouts/master", DisableCache: true})
+	e.SetFileHandler(func(config Config, tplFile string) (string, error) {
+		reads[tplFile+config.Extension]++
+		content, ok := files[tplFile+config.Extension]
+		if !ok {
+			return "", fmt.Errorf("file %s%s: %w", tplFile, config.Extension, fs.ErrNotExist)
+		}
+		return content, nil
+	})
+
+	rec := httptest.NewRecorder()
+	if err := e.Render(rec, http.StatusOK, "index", nil); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Body.String() != "<main>Home</main>" || rec.Header().Get("Cache-Control") != "max-age=60" {
+		t.Errorf("got %q %v", rec.Body.String(), rec.Header())
+	}
+	// once for the front matter, once to parse
+	if reads["index.html"] != 2 {
+		t.Errorf("index.html read %d times, want 2", reads["index.html"])
+	}
+}
@@ -0,0 +1,165 @@
+package goview
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+const (
+	// BuiltinPack is the pack name of the funcs provided by goview itself, such as `include`.
+	BuiltinPack = "builtin"
+	// ConfigPack is the pack name of the funcs set by Config.Funcs.
+	ConfigPack = "config"
+)
+
+// FuncInfo describes an effective template func and the pack providing it.
+type FuncInfo struct {
+	Name string
+	Pack string
+}
+
+// FuncConflict describes a func name provided by more than one pack.
+type FuncConflict struct {
+	Name  string //func name
+	Pack  string //pack being registered
+	Owner string //pack already providing the name
+}
+
+// FuncConflictError lists the conflicts found while registering a func pack.
+type FuncConflictError []FuncConflict
+
+// Error method
+func (fe FuncConflictError) Error() string {
+	msgs := make([]string, 0, len(fe))
+	for _, c := range fe {
+		msgs = append(msgs, fmt.Sprintf("func %q of pack %q already provided by pack %q", c.Name, c.Pack, c.Owner))
+	}
+	return "ViewEngine func conflict: " + strings.Join(msgs, "; ")
+}
+
+type funcPack struct {
+	name  string
+	funcs template.FuncMap
+}
+
+// RegisterFuncs registers a named pack of template funcs, such as "i18n" or "assets".
+// It returns a FuncConflictError if any func name is already provided by
+// a built-in func, Config.Funcs or another pack; nothing is registered in that case.
+func (e *ViewEngine) RegisterFuncs(pack string, funcs template.FuncMap) error {
+	if pack == "" || pack == BuiltinPack || pack == ConfigPack {
+		return fmt.Errorf("ViewEngine invalid func pack name: %q", pack)
+	}
+
+	e.funcMutex.Lock()
+	defer e.funcMutex.Unlock()
+
+	for _, p := range e.funcPacks {
+		if p.name == pack {
+			return fmt.Errorf("ViewEngine func pack %q already registered", pack)
+		}
+	}
+
+	owners := e.funcOwners()
+	var conflicts FuncConflictError
+	for _, name := range sortedFuncNames(funcs) {
+		if owner, ok := owners[name]; ok {
+			conflicts = append(conflicts, FuncConflict{Name: name, Pack: pack, Owner: owner})
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts
+	}
+
+	packFuncs := make(template.FuncMap, len(funcs))
+	for k, v := range funcs {
+		packFuncs[k] = v
+	}
+	e.funcPacks = append(e.funcPacks, funcPack{name: pack, funcs: packFuncs})
+
+	// Cached templates were parsed without the new funcs.
+	e.tplMutex.Lock()
+	e.tplMap = make(map[string]*parsedTmpl)
+	e.tplMutex.Unlock()
+	return nil
+}
+
+// MustRegisterFuncs is like RegisterFuncs but panics on error.
+func (e *ViewEngine) MustRegisterFuncs(pack string, funcs template.FuncMap) {
+	if err := e.RegisterFuncs(pack, funcs); err != nil {
+		panic(err)
+	}
+}
+
+// CheckFuncs reports the Config.Funcs that override a built-in func.
+func (e *ViewEngine) CheckFuncs() error {
+	var conflicts FuncConflictError
+	builtins := e.builtinFuncs(&binding{}, false)
+	for _, name := range sortedFuncNames(e.config.Funcs) {
+		if _, ok := builtins[name]; ok {
+			conflicts = append(conflicts, FuncConflict{Name: name, Pack: ConfigPack, Owner: BuiltinPack})
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts
+	}
+	return nil
+}
+
+// Funcs returns the effective template funcs sorted by name, for debugging.
+func (e *ViewEngine) Funcs() []FuncInfo {
+	e.funcMutex.RLock()
+	owners := e.funcOwners()
+	e.funcMutex.RUnlock()
+
+	infos := make([]FuncInfo, 0, len(owners))
+	for name, pack := range owners {
+		infos = append(infos, FuncInfo{Name: name, Pack: pack})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// funcOwners maps every func name to the pack providing it, callers must hold funcMutex.
+func (e *ViewEngine) funcOwners() map[string]string {
+	owners := make(map[string]string)
+	for name := range e.builtinFuncs(&binding{}, false) {
+		owners[name] = BuiltinPack
+	}
+	// Config.Funcs may override built-in funcs for backward compatibility.
+	for name := range e.config.Funcs {
+		owners[name] = ConfigPack
+	}
+	for _, p := range e.funcPacks {
+		for name := range p.funcs {
+			owners[name] = p.name
+		}
+	}
+	return owners
+}
+
+// allFuncs merges built-in funcs, Config.Funcs and the registered packs.
+func (e *ViewEngine) allFuncs(b *binding, text bool) template.FuncMap {
+	funcs := e.builtinFuncs(b, text)
+	for k, v := range e.config.Funcs {
+		funcs[k] = v
+	}
+	e.funcMutex.RLock()
+	for _, p := range e.funcPacks {
+		for k, v := range p.funcs {
+			funcs[k] = v
+		}
+	}
+	e.funcMutex.RUnlock()
+	return funcs
+}
+
+func sortedFuncNames(funcs template.FuncMap) []string {
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,67 @@
+package goview
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestRegisterFuncs(t *testing.T) {
+	e := newTestEngine(Config{Extension: ".html", Funcs: template.FuncMap{"upper": strings.ToUpper}}, map[string]string{
+		"index.html": `{{upper (hello .)}}`,
+	})
+
+	if err := e.RegisterFuncs("greet", template.FuncMap{"hello": func(s string) string { return "hello " + s }}); err != nil {
+		t.Fatalf("register greet: %v", err)
+	}
+
+	err := e.RegisterFuncs("other", template.FuncMap{"include": nil, "upper": nil, "hello": nil, "free": nil})
+	conflicts, ok := err.(FuncConflictError)
+	if !ok {
+		t.Fatalf("expected FuncConflictError, got %v", err)
+	}
+	want := FuncConflictError{
+		{Name: "hello", Pack: "other", Owner: "greet"},
+		{Name: "include", Pack: "other", Owner: BuiltinPack},
+		{Name: "upper", Pack: "other", Owner: ConfigPack},
+	}
+	if len(conflicts) != len(want) {
+		t.Fatalf("conflicts = %v, want %v", conflicts, want)
+	}
+	for i := range want {
+		if conflicts[i] != want[i] {
+			t.Errorf("conflict[%d] = %v, want %v", i, conflicts[i], want[i])
+		}
+	}
+
+	if err := e.RegisterFuncs("greet", template.FuncMap{"bye": nil}); err == nil {
+		t.Error("expected error registering a pack twice")
+	}
+
+	packs := make(map[string]string)
+	for _, info := range e.Funcs() {
+		packs[info.Name] = info.Pack
+	}
+	if packs["hello"] != "greet" || packs["include"] != BuiltinPack || packs["upper"] != ConfigPack {
+		t.Errorf("unexpected funcs: %v", packs)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := e.RenderWriter(buf, "index.html", "goview"); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "HELLO GOVIEW" {
+		t.Errorf("render = %q", got)
+	}
+}
+
+func TestCheckFuncs(t *testing.T) {
+	e := New(Config{Funcs: template.FuncMap{"include": nil}})
+	if err := e.CheckFuncs(); err == nil {
+		t.Error("expected Config.Funcs overriding include to be reported")
+	}
+	if err := Default().CheckFuncs(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,12 +1,39 @@
 module github.com/go-tea/goview
 
-go 1.13
+go 1.20
 
 require (
 	github.com/GeertJohan/go.rice v1.0.0
 	github.com/elazarl/go-bindata-assetfs v1.0.0
+	github.com/fxamacker/cbor/v2 v2.9.4
 	github.com/gin-gonic/gin v1.4.0
 	github.com/labstack/echo v3.3.10+incompatible
-	github.com/labstack/gommon v0.2.9 // indirect
+	github.com/microcosm-cc/bluemonday v1.0.27
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	github.com/yuin/goldmark v1.7.8
+	golang.org/x/net v0.34.0
 	gopkg.in/yaml.v2 v2.2.2
 )
+
+require (
+	github.com/aymerick/douceur v0.2.0 // indirect
+	github.com/daaku/go.zipexe v1.0.0 // indirect
+	github.com/gin-contrib/sse v0.0.0-20190301062529-5545eab6dad3 // indirect
+	github.com/golang/protobuf v1.3.1 // indirect
+	github.com/gorilla/css v1.0.1 // indirect
+	github.com/json-iterator/go v1.1.6 // indirect
+	github.com/labstack/gommon v0.2.9 // indirect
+	github.com/mattn/go-colorable v0.1.2 // indirect
+	github.com/mattn/go-isatty v0.0.8 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.1 // indirect
+	github.com/ugorji/go v1.1.4 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.0.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/crypto v0.32.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	gopkg.in/go-playground/validator.v8 v8.18.2 // indirect
+)
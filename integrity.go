@@ -0,0 +1,128 @@
+package goview
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// IntegrityPack is the func pack name used by UseIntegrity.
+const IntegrityPack = "integrity"
+
+// integrity computes and caches Subresource Integrity hashes of asset files.
+type integrity struct {
+	engine *ViewEngine
+	fs     http.FileSystem
+	prefix string
+	mutex  sync.RWMutex
+	hashes map[string]string
+}
+
+// UseIntegrity registers template funcs emitting Subresource Integrity attributes,
+// the hashes are computed from the asset files of fs and cached per engine:
+//
+//	{{integrity "js/app.js"}}  -> sha384-...
+//	{{scriptTag "js/app.js"}}  -> <script src="/static/js/app.js" integrity="sha384-..." crossorigin="anonymous"></script>
+//	{{styleTag "css/app.css"}} -> <link rel="stylesheet" href="/static/css/app.css" integrity="sha384-..." crossorigin="anonymous">
+//
+// Urls use the `asset` manifest when UseAssets was called, otherwise prefix is prepended.
+func (e *ViewEngine) UseIntegrity(fs http.FileSystem, prefix string) error {
+	sri := &integrity{
+		engine: e,
+		fs:     fs,
+		prefix: prefix,
+		hashes: make(map[string]string),
+	}
+	return e.RegisterFuncs(IntegrityPack, template.FuncMap{
+		"integrity": sri.hash,
+		"scriptTag": sri.scriptTag,
+		"styleTag":  sri.styleTag,
+	})
+}
+
+func (i *integrity) scriptTag(name string) (template.HTML, error) {
+	src, sum, err := i.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(fmt.Sprintf(`<script src="%s" integrity="%s" crossorigin="anonymous"></script>`,
+		template.HTMLEscapeString(src), sum)), nil
+}
+
+func (i *integrity) styleTag(name string) (template.HTML, error) {
+	href, sum, err := i.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(fmt.Sprintf(`<link rel="stylesheet" href="%s" integrity="%s" crossorigin="anonymous">`,
+		template.HTMLEscapeString(href), sum)), nil
+}
+
+// resolve returns the url and integrity of a logical asset name.
+func (i *integrity) resolve(name string) (url, sum string, err error) {
+	if m := i.engine.assetManifest(); m != nil {
+		url, err = m.URL(name)
+	} else {
+		url = strings.TrimSuffix(i.prefix, "/") + "/" + cleanAssetPath(name)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	sum, err = i.hash(name)
+	return url, sum, err
+}
+
+// hash returns the sha384 integrity of an asset, cached unless DisableCache is set.
+func (i *integrity) hash(name string) (string, error) {
+	name = cleanAssetPath(name)
+	cache := !i.engine.config.DisableCache
+	if cache {
+		i.mutex.RLock()
+		sum, ok := i.hashes[name]
+		i.mutex.RUnlock()
+		if ok {
+			return sum, nil
+		}
+	}
+
+	// The file on disk may have the hashed name, such as with a Vite manifest.
+	files := []string{name}
+	if m := i.engine.assetManifest(); m != nil {
+		if hashed, ok := m.Lookup(name); ok && !strings.Contains(hashed, "://") {
+			files = append([]string{strings.TrimPrefix(strings.TrimPrefix(hashed, strings.TrimSuffix(m.prefix, "/")), "/")}, files...)
+		}
+	}
+
+	var err error
+	for _, file := range files {
+		var sum string
+		if sum, err = i.compute(file); err == nil {
+			if cache {
+				i.mutex.Lock()
+				i.hashes[name] = sum
+				i.mutex.Unlock()
+			}
+			return sum, nil
+		}
+	}
+	return "", fmt.Errorf("ViewEngine integrity asset:%v, error: %v", name, err)
+}
+
+func (i *integrity) compute(file string) (string, error) {
+	f, err := i.fs.Open(path.Join("/", file))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha512.New384()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha384-" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,129 @@
+package goview
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path"
+	"time"
+)
+
+// MarkdownContentKey is the data key of the rendered markdown in the layout of a markdown view,
+// the layout includes it with `{{template "content" .}}`.
+const MarkdownContentKey = "content"
+
+// MarkdownFunc converts markdown to HTML, see the markdown package.
+type MarkdownFunc func(src string) (template.HTML, error)
+
+var errNoMarkdown = errors.New("no markdown converter, see SetMarkdown")
+
+// markdownFile is a parsed markdown view.
+type markdownFile struct {
+	meta    *Meta
+	html    template.HTML
+	modTime time.Time //zero when unknown
+}
+
+// SetMarkdown sets the converter of the Config.Markdown views, markdown.Use sets it with the
+// `markdown` func. Markdown views fail without a converter.
+func (e *ViewEngine) SetMarkdown(convert MarkdownFunc) {
+	e.tplMutex.Lock()
+	e.markdown = convert
+	e.mdMap = make(map[string]*markdownFile)
+	e.tplMutex.Unlock()
+}
+
+// markdownSource returns the template of a markdown view, it renders the markdown without layout
+// and defines "content" for the layout.
+func (e *ViewEngine) markdownSource() string {
+	l, r := e.config.Delims.Left, e.config.Delims.Right
+	if l == "" {
+		l = "{{"
+	}
+	if r == "" {
+		r = "}}"
+	}
+	content := l + "." + MarkdownContentKey + r
+	return content + l + `define "content"` + r + content + l + "end" + r
+}
+
+// isMarkdown reports whether name is a markdown view, with the Config.Markdown extension.
+func (e *ViewEngine) isMarkdown(name string) bool {
+	return e.config.Markdown != "" && !e.text && path.Ext(name) == e.config.Markdown
+}
+
+// markdownData returns the data of the layout of a markdown view: the title and params of the
+// front matter, the render data when it is a map, or under the key "data" otherwise, and the
+// HTML under "content".
+func markdownData(md *markdownFile, data interface{}) interface{} {
+	m := make(M, len(md.meta.Params)+2)
+	for k, v := range md.meta.Params {
+		m[k] = v
+	}
+	if md.meta.Title != "" {
+		m["title"] = md.meta.Title
+	}
+	switch d := data.(type) {
+	case nil:
+	case M:
+		for k, v := range d {
+			m[k] = v
+		}
+	case map[string]interface{}:
+		for k, v := range d {
+			m[k] = v
+		}
+	default:
+		m["data"] = d
+	}
+	m[MarkdownContentKey] = md.html
+	return m
+}
+
+func (e *ViewEngine) loadMarkdown(name string) (*markdownFile, error) {
+	e.tplMutex.RLock()
+	md, ok := e.mdMap[name]
+	e.tplMutex.RUnlock()
+	if ok && !e.config.DisableCache {
+		return md, nil
+	}
+
+	e.tplMutex.RLock()
+	convert := e.markdown
+	e.tplMutex.RUnlock()
+	if convert == nil {
+		se := new(StatusError)
+		se.Code = http.StatusInternalServerError
+		se.Err = fmt.Errorf("ViewEngine markdown name:%v, error: %w", name, errNoMarkdown)
+		return nil, se
+	}
+
+	src, err := e.readFile(name)
+	if err != nil {
+		return nil, fileHandlerError(err)
+	}
+	front, body, _ := splitFrontMatter(string(src))
+	meta, err := parseMeta(front)
+	if err != nil {
+		se := new(StatusError)
+		se.Code = http.StatusInternalServerError
+		se.Err = fmt.Errorf("ViewEngine front matter name:%v, error: %v", name, err)
+		return nil, se
+	}
+	html, err := convert(body)
+	if err != nil {
+		se := new(StatusError)
+		se.Code = http.StatusInternalServerError
+		se.Err = fmt.Errorf("ViewEngine markdown name:%v, error: %v", name, err)
+		return nil, se
+	}
+
+	config := e.config
+	config.Extension = ""
+	md = &markdownFile{meta: meta, html: html, modTime: e.fileModTime(config, name)}
+	e.tplMutex.Lock()
+	e.mdMap[name] = md
+	e.tplMutex.Unlock()
+	return md, nil
+}
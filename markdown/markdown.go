@@ -0,0 +1,62 @@
+/*
+Package markdown renders markdown views and the `markdown` template func of a goview.ViewEngine,
+with goldmark (GitHub flavored markdown) and bluemonday sanitizing. It keeps both dependencies out
+of the goview package, markdown views fail until Use is called.
+
+Example:
+
+	gv := goview.New(goview.Config{
+		Root:     "views",
+		Master:   "layouts/master",
+		Markdown: ".md",
+	})
+	if err := markdown.Use(gv); err != nil {
+		return err
+	}
+
+	gv.Render(w, http.StatusOK, "docs/intro.md", goview.M{"user": user})
+*/
+package markdown
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/go-tea/goview"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// Pack is the name of the func pack registered by Use.
+const Pack = "markdown"
+
+var (
+	markdownParser = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()), // sanitized by markdownPolicy
+	)
+	markdownPolicy = bluemonday.UGCPolicy()
+)
+
+// Render renders markdown to sanitized HTML.
+func Render(src string) (template.HTML, error) {
+	buf := new(bytes.Buffer)
+	if err := markdownParser.Convert([]byte(src), buf); err != nil {
+		return "", err
+	}
+	return template.HTML(markdownPolicy.SanitizeBytes(buf.Bytes())), nil
+}
+
+// Use renders the markdown views of the engine with Render and registers the `markdown` func
+// in the Pack func pack.
+func Use(engine *goview.ViewEngine) error {
+	if err := engine.RegisterFuncs(Pack, template.FuncMap{"markdown": Render}); err != nil {
+		return err
+	}
+	engine.SetMarkdown(Render)
+	return nil
+}
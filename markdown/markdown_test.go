@@ -0,0 +1,49 @@
+package markdown
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-tea/goview"
+)
+
+func TestMarkdownView(t *testing.T) {
+	e := goview.New(goview.Config{Extension: ".html", Master: "layouts/master", Markdown: ".md", Delims: goview.Delims{Left: "[[", Right: "]]"}})
+	e.SetFileHandler(goview.MapFileHandler(map[string]string{
+		"layouts/master.html": `<title>[[.title]]</title><main>[[template "content" .]]</main><p>[[.user]]</p>`,
+		"layouts/docs.html":   `<article>[[template "content" .]]</article>`,
+		"about.md":            "---\ntitle: About {{us}}\n---\n# Hello\n\nSome *text* {{.user}}<script>alert(1)</script>\n",
+		"docs/intro.md":       "---\nlayout: layouts/docs\n---\nIntro",
+		"plain.md":            "---\nlayout:\n---\nPlain `code`",
+		"page.html":           `[[markdown .]]`,
+	}))
+	if err := Use(e); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{"about.md", goview.M{"user": "tom"}, `<title>About {{us}}</title><main><h1 id="hello">Hello</h1>
+<p>Some <em>text</em> {{.user}}</p>
+</main><p>tom</p>`},
+		{"docs/intro.md", nil, "<article><p>Intro</p>\n</article>"},
+		{"plain.md", nil, "<p>Plain <code>code</code></p>\n"},
+		{"page.html", "**bold** <img src=x onerror=alert(1)>", "<p><strong>bold</strong> <img src=\"x\"></p>\n"},
+	} {
+		rec := httptest.NewRecorder()
+		if err := e.Render(rec, http.StatusOK, tt.name, tt.data); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if rec.Body.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, rec.Body.String(), tt.want)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("%s: Content-Type = %q", tt.name, ct)
+		}
+	}
+}
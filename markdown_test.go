@@ -0,0 +1,35 @@
+package goview
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownView(t *testing.T) {
+	e := newTestEngine(Config{Extension: ".html", Master: "layouts/master", Markdown: ".md"}, map[string]string{
+		"layouts/master.html": `<title>{{.title}}</title><main>{{template "content" .}}</main><p>{{.user}}</p>`,
+		"about.md":            "---\ntitle: About\n---\nHello <b>",
+	})
+	if err := e.Render(httptest.NewRecorder(), http.StatusOK, "about.md", nil); !errors.Is(err, errNoMarkdown) {
+		t.Errorf("without converter: err = %v", err)
+	}
+
+	// the converter output is trusted HTML
+	e.SetMarkdown(func(src string) (template.HTML, error) {
+		return template.HTML("<pre>" + strings.TrimSpace(src) + "</pre>"), nil
+	})
+	rec := httptest.NewRecorder()
+	if err := e.Render(rec, http.StatusOK, "about.md", M{"user": "tom"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<title>About</title><main><pre>Hello <b></pre></main><p>tom</p>"; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
@@ -0,0 +1,245 @@
+package goview
+
+import (
+	"bytes"
+	"io"
+)
+
+// blockTags are the elements around which whitespace is insignificant.
+var blockTags = map[string]bool{
+	"!doctype": true, "html": true, "head": true, "body": true, "title": true, "meta": true, "link": true,
+	"script": true, "style": true, "noscript": true, "base": true, "div": true, "p": true, "pre": true,
+	"blockquote": true, "ul": true, "ol": true, "li": true, "dl": true, "dt": true, "dd": true,
+	"table": true, "caption": true, "colgroup": true, "col": true, "thead": true, "tbody": true,
+	"tfoot": true, "tr": true, "td": true, "th": true, "form": true, "fieldset": true, "legend": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "br": true,
+	"header": true, "footer": true, "nav": true, "main": true, "section": true, "article": true,
+	"aside": true, "figure": true, "figcaption": true, "address": true, "details": true,
+	"summary": true, "option": true, "optgroup": true, "template": true,
+}
+
+// rawTags are the elements whose content is preserved as is.
+var rawTags = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// minifyWriter removes insignificant whitespace and comments from the html written to it.
+// The content of <pre>, <textarea>, <script> and <style> is preserved. It is
+// streaming: incomplete tags and comments are kept until the next Write or Close.
+type minifyWriter struct {
+	w       io.Writer
+	buf     []byte //unprocessed input
+	out     []byte //output of the current write
+	raw     []byte //closing tag of the raw element being copied, such as "</script"
+	space   bool   //pending whitespace in text
+	started bool   //output started, leading whitespace is dropped
+	block   bool   //last output is a block tag
+}
+
+func newMinifyWriter(w io.Writer) *minifyWriter {
+	return &minifyWriter{w: w}
+}
+
+// Write method
+func (m *minifyWriter) Write(p []byte) (int, error) {
+	m.buf = append(m.buf, p...)
+	if err := m.process(false); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close flushes the remaining input, it does not close the underlying writer.
+func (m *minifyWriter) Close() error {
+	return m.process(true)
+}
+
+func (m *minifyWriter) process(final bool) error {
+	buf := m.buf
+	out := m.out[:0]
+	i := 0
+loop:
+	for i < len(buf) {
+		if m.raw != nil {
+			j := indexFold(buf[i:], m.raw)
+			if j < 0 {
+				// keep a possible partial closing tag for the next write
+				keep := len(buf) - len(m.raw) + 1
+				if final {
+					keep = len(buf)
+				}
+				if keep > i {
+					out = append(out, buf[i:keep]...)
+					i = keep
+				}
+				break
+			}
+			out = append(out, buf[i:i+j]...)
+			i += j
+			m.raw = nil
+			continue
+		}
+
+		c := buf[i]
+		if c != '<' {
+			if isHTMLSpace(c) {
+				m.space = true
+			} else {
+				out = m.text(out, c)
+			}
+			i++
+			continue
+		}
+
+		rest := buf[i:]
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			end := bytes.Index(rest[4:], []byte("-->"))
+			if end < 0 {
+				if !final {
+					break loop
+				}
+				end = len(rest) - 7
+			}
+			comment := rest[:end+7]
+			if bytes.HasPrefix(comment, []byte("<!--[if")) || bytes.HasPrefix(comment, []byte("<!--<![endif")) {
+				// keep conditional comments
+				out = m.emitSpace(out, false)
+				out = append(out, comment...)
+				m.started = true
+			}
+			i += len(comment)
+		case len(rest) < 2 || len(rest) < 4 && rest[1] == '!':
+			if !final {
+				break loop
+			}
+			out = m.text(out, c)
+			i++
+		case !isTagStart(rest[1]):
+			out = m.text(out, c)
+			i++
+		default:
+			end := tagEnd(rest)
+			if end < 0 {
+				if !final {
+					break loop
+				}
+				out = append(out, rest...)
+				i = len(buf)
+				break loop
+			}
+			tag := rest[:end+1]
+			name, closing := tagName(tag)
+			block := blockTags[name]
+			out = m.emitSpace(out, block)
+			out = appendTag(out, tag)
+			m.started = true
+			m.block = block
+			if !closing && rawTags[name] && !bytes.HasSuffix(tag, []byte("/>")) {
+				m.raw = append([]byte("</"), name...)
+			}
+			i += end + 1
+		}
+	}
+
+	n := copy(m.buf, buf[i:])
+	m.buf = m.buf[:n]
+	m.out = out
+	if len(out) == 0 {
+		return nil
+	}
+	_, err := m.w.Write(out)
+	return err
+}
+
+// text appends a text byte, preceded by the pending whitespace.
+func (m *minifyWriter) text(out []byte, c byte) []byte {
+	out = m.emitSpace(out, false)
+	m.started = true
+	m.block = false
+	return append(out, c)
+}
+
+// emitSpace appends the pending whitespace unless it is next to a block tag.
+func (m *minifyWriter) emitSpace(out []byte, block bool) []byte {
+	if m.space && m.started && !block && !m.block {
+		out = append(out, ' ')
+	}
+	m.space = false
+	return out
+}
+
+// appendTag appends a tag with whitespace outside of quoted values collapsed.
+func appendTag(out, tag []byte) []byte {
+	var quote byte
+	space := false
+	for _, c := range tag {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case isHTMLSpace(c):
+			space = true
+			continue
+		}
+		if space && c != '>' {
+			out = append(out, ' ')
+		}
+		space = false
+		out = append(out, c)
+	}
+	return out
+}
+
+// tagEnd returns the index of the '>' ending the tag at the start of b, or -1.
+func tagEnd(b []byte) int {
+	var quote byte
+	for i, c := range b {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+// tagName returns the lower case name of a tag and whether it is a closing tag.
+func tagName(tag []byte) (string, bool) {
+	i := 1
+	closing := i < len(tag) && tag[i] == '/'
+	if closing {
+		i++
+	}
+	start := i
+	for i < len(tag) && (isTagStart(tag[i]) && tag[i] != '/' || tag[i] >= '0' && tag[i] <= '9' || tag[i] == '-') {
+		i++
+	}
+	return string(bytes.ToLower(tag[start:i])), closing
+}
+
+func isTagStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '/' || c == '!'
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// indexFold is a case insensitive bytes.Index for ascii.
+func indexFold(s, sep []byte) int {
+	for i := 0; i+len(sep) <= len(s); i++ {
+		if bytes.EqualFold(s[i:i+len(sep)], sep) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,111 @@
+package goview
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const minifyPage = `<!doctype html>
+
+<html>
+    <head>
+        <title>Page</title>
+        <!-- head comment -->
+        <link  rel="stylesheet"
+               href="/static/css/app.css" >
+        <style>
+            body  { margin: 0 }
+        </style>
+    </head>
+    <body>
+        <div class="a   b">
+            <p>Hello   <b>world</b>  !</p>
+            <pre>
+  keep   this
+            </pre>
+            <textarea name="t">  a
+  b </textarea>
+        </div>
+        <script>
+            if (a < b) { console.log("  spaced  ") }
+        </script>
+        <!--[if IE]><p>IE</p><![endif]-->
+    </body>
+</html>
+`
+
+const minifyWant = `<!doctype html><html><head><title>Page</title><link rel="stylesheet" href="/static/css/app.css"><style>
+            body  { margin: 0 }
+        </style></head><body><div class="a   b"><p>Hello <b>world</b> !</p><pre>
+  keep   this
+            </pre><textarea name="t">  a
+  b </textarea></div><script>
+            if (a < b) { console.log("  spaced  ") }
+        </script><!--[if IE]><p>IE</p><![endif]--></body></html>`
+
+func minify(t testing.TB, src string, chunk int) string {
+	buf := new(bytes.Buffer)
+	mw := newMinifyWriter(buf)
+	for i := 0; i < len(src); i += chunk {
+		end := i + chunk
+		if end > len(src) {
+			end = len(src)
+		}
+		if _, err := mw.Write([]byte(src[i:end])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestMinify(t *testing.T) {
+	// every chunk size must give the same output as a single write
+	for _, chunk := range []int{len(minifyPage), 1, 2, 3, 7, 64} {
+		if got := minify(t, minifyPage, chunk); got != minifyWant {
+			t.Errorf("chunk %d:\n got %q\nwant %q", chunk, got, minifyWant)
+		}
+	}
+	if got := minify(t, "a < b <!-- unterminated", 4); got != "a < b" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRenderMinify(t *testing.T) {
+	e := newTestEngine(Config{Extension: ".html", Minify: true}, map[string]string{
+		"index.html":  "<div>\n  {{include \"footer\"}}\n</div>\n",
+		"footer.html": "<footer>\n  <p> {{.}} </p>\n</footer>",
+	})
+	buf := new(bytes.Buffer)
+	if err := e.RenderWriter(buf, "index.html", "bye"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<div><footer><p>bye</p></footer></div>"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	benchmarkRender(b, false)
+}
+
+func BenchmarkRenderMinify(b *testing.B) {
+	benchmarkRender(b, true)
+}
+
+func benchmarkRender(b *testing.B, minify bool) {
+	e := newTestEngine(Config{Extension: ".html", Minify: minify}, map[string]string{
+		"index.html": strings.Repeat(minifyPage, 4),
+	})
+	b.SetBytes(int64(4 * len(minifyPage)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := e.RenderWriter(ioutil.Discard, "index.html", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
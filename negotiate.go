@@ -0,0 +1,171 @@
+package goview
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-tea/goview/renderer"
+)
+
+// Negotiated formats
+const (
+	FormatHTML    = "html"
+	FormatJSON    = "json"
+	FormatXML     = "xml"
+	FormatYAML    = "yaml"
+	FormatMsgPack = "msgpack"
+	FormatCBOR    = "cbor"
+)
+
+// offers in order of preference, html first for browsers sending */*
+var offers = []struct {
+	format string
+	types  []string
+}{
+	{FormatHTML, []string{"text/html", "application/xhtml+xml"}},
+	{FormatJSON, []string{"application/json"}},
+	{FormatXML, []string{"application/xml", "text/xml"}},
+	{FormatYAML, []string{"application/x-yaml", "application/yaml", "text/yaml"}},
+	{FormatMsgPack, []string{"application/x-msgpack", "application/msgpack", "application/vnd.msgpack"}},
+	{FormatCBOR, []string{"application/cbor"}},
+}
+
+// Negotiate renders the view name as html, or the same data as JSON, XML, YAML,
+// MessagePack or CBOR with the engine renderer, according to the Accept header. The query param
+// Config.FormatParam, such as `?format=json`, takes precedence over Accept.
+// XML is not offered for map data, which encoding/xml cannot marshal.
+// It responds 406 Not Acceptable when no format matches, and always sets `Vary: Accept`.
+func (e *ViewEngine) Negotiate(w http.ResponseWriter, r *http.Request, statusCode int, name string, data interface{}) error {
+	w.Header().Add("Vary", "Accept")
+
+	canXML := xmlData(data)
+	format := ""
+	if e.config.FormatParam != "" {
+		format = r.URL.Query().Get(e.config.FormatParam)
+	}
+	if format == "" {
+		format = negotiateFormat(r.Header.Get("Accept"), canXML)
+	}
+	if format == FormatXML && !canXML {
+		format = ""
+	}
+
+	switch format {
+	case FormatHTML:
+		return e.RenderRequest(w, r, statusCode, name, data)
+	case FormatJSON:
+		return e.dataRenderer().JSON(w, statusCode, data)
+	case FormatXML:
+		return e.dataRenderer().XML(w, statusCode, data)
+	case FormatYAML:
+		return e.dataRenderer().YAML(w, statusCode, data)
+	case FormatMsgPack:
+		return e.dataRenderer().MsgPack(w, statusCode, data)
+	case FormatCBOR:
+		return e.dataRenderer().CBOR(w, statusCode, data)
+	}
+	http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+	return nil
+}
+
+// SetRenderer sets the renderer used by Negotiate for data formats, renderer.Default() by default.
+func (e *ViewEngine) SetRenderer(r *renderer.Renderer) {
+	e.renderer = r
+}
+
+func (e *ViewEngine) dataRenderer() *renderer.Renderer {
+	if e.renderer != nil {
+		return e.renderer
+	}
+	return renderer.Default()
+}
+
+// NegotiateFormat returns the format best matching an Accept header, or "" if none does.
+// An empty Accept header accepts html.
+func NegotiateFormat(accept string) string {
+	return negotiateFormat(accept, true)
+}
+
+// negotiateFormat is NegotiateFormat, leaving XML out of the offers unless canXML.
+func negotiateFormat(accept string, canXML bool) string {
+	if strings.TrimSpace(accept) == "" {
+		return FormatHTML
+	}
+	ranges := parseAccept(accept)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if offer.format == FormatXML && !canXML {
+			continue
+		}
+		for i, t := range offer.types {
+			// alternate types only count when listed explicitly, not by wildcard
+			if q, exact := acceptQuality(ranges, t); (i == 0 || exact) && q > bestQ {
+				best, bestQ = offer.format, q
+			}
+		}
+	}
+	return best
+}
+
+// xmlData reports whether data can be marshaled as XML, encoding/xml rejects maps such as M.
+func xmlData(data interface{}) bool {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return v.Kind() != reflect.Map
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		mt := strings.ToLower(strings.TrimSpace(fields[0]))
+		slash := strings.IndexByte(mt, '/')
+		if slash < 0 {
+			continue
+		}
+		mr := mediaRange{typ: mt[:slash], subtype: mt[slash+1:], q: 1}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					mr.q = q
+				}
+			}
+		}
+		ranges = append(ranges, mr)
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific range matching the media type,
+// and whether that range is the exact type.
+func acceptQuality(ranges []mediaRange, mediaType string) (float64, bool) {
+	slash := strings.IndexByte(mediaType, '/')
+	typ, subtype := mediaType[:slash], mediaType[slash+1:]
+	q, specificity := 0.0, -1
+	for _, mr := range ranges {
+		s := -1
+		switch {
+		case mr.typ == typ && mr.subtype == subtype:
+			s = 2
+		case mr.typ == typ && mr.subtype == "*":
+			s = 1
+		case mr.typ == "*" && mr.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = mr.q, s
+		}
+	}
+	return q, specificity == 2
+}
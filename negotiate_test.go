@@ -0,0 +1,67 @@
+package goview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		accept, want string
+	}{
+		{"", FormatHTML},
+		{"*/*", FormatHTML},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", FormatHTML},
+		{"application/json", FormatJSON},
+		{"application/json;q=0.5, application/xml", FormatXML},
+		{"text/*;q=0.5, application/x-yaml", FormatYAML},
+		{"text/*", FormatHTML},
+		{"*/*;q=0.1, text/html;q=0", FormatJSON},
+		{"application/cbor, application/json;q=0.9", FormatCBOR},
+		{"application/vnd.msgpack", FormatMsgPack},
+		{"image/png", ""},
+	}
+	for _, tt := range tests {
+		if got := NegotiateFormat(tt.accept); got != tt.want {
+			t.Errorf("NegotiateFormat(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	e := newTestEngine(Config{Extension: ".html", FormatParam: "format"}, map[string]string{
+		"index.html": `<p>{{.title}}</p>`,
+	})
+	data := M{"title": "hello"}
+
+	tests := []struct {
+		url, accept string
+		code        int
+		body        string
+	}{
+		{"/", "text/html", http.StatusOK, "<p>hello</p>"},
+		{"/", "application/json", http.StatusOK, `{"title":"hello"}`},
+		{"/?format=json", "text/html", http.StatusOK, `{"title":"hello"}`},
+		{"/", "image/png", http.StatusNotAcceptable, "Not Acceptable\n"},
+		{"/?format=csv", "", http.StatusNotAcceptable, "Not Acceptable\n"},
+		// M data cannot be marshaled as XML
+		{"/", "application/xml", http.StatusNotAcceptable, "Not Acceptable\n"},
+		{"/", "application/xml, application/json;q=0.5", http.StatusOK, `{"title":"hello"}`},
+		{"/?format=xml", "", http.StatusNotAcceptable, "Not Acceptable\n"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		req.Header.Set("Accept", tt.accept)
+		rec := httptest.NewRecorder()
+		if err := e.Negotiate(rec, req, http.StatusOK, "index.html", data); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != tt.code || rec.Body.String() != tt.body {
+			t.Errorf("%s %s: got %d %q, want %d %q", tt.url, tt.accept, rec.Code, rec.Body.String(), tt.code, tt.body)
+		}
+		if rec.Header().Get("Vary") != "Accept" {
+			t.Errorf("%s %s: missing Vary", tt.url, tt.accept)
+		}
+	}
+}
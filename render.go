@@ -30,7 +30,7 @@ func (e *ViewEngine) Instance(name string, data ...map[string]interface{}) ViewR
 
 // Render method
 func (r ViewRender) Render(w http.ResponseWriter) {
-	err := r.Engine.executeRender(w, r.Name, r.Vars)
+	err := r.Engine.RenderWriter(w, r.Name, r.Vars)
 	if err != nil {
 		switch t := err.(type) {
 		case IStatusError:
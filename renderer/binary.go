@@ -0,0 +1,46 @@
+package renderer
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgPack serve data as MessagePack response
+func MsgPack(w http.ResponseWriter, status int, v interface{}) error {
+	return std.MsgPack(w, status, v)
+}
+
+// CBOR serve data as CBOR response
+func CBOR(w http.ResponseWriter, status int, v interface{}) error {
+	return std.CBOR(w, status, v)
+}
+
+// MsgPack serve data as MessagePack response, struct fields use their `json` tags like JSON.
+func (r *Renderer) MsgPack(w http.ResponseWriter, status int, v interface{}) error {
+	buf := new(bytes.Buffer)
+	enc := msgpack.NewEncoder(buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	setContentType(w, ContentMsgPack)
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// CBOR serve data as CBOR response, struct fields use their `json` tags like JSON
+// unless a `cbor` tag is set.
+func (r *Renderer) CBOR(w http.ResponseWriter, status int, v interface{}) error {
+	bs, err := cbor.Marshal(v)
+	if err != nil {
+		return err
+	}
+	setContentType(w, ContentCBOR)
+	w.WriteHeader(status)
+	_, err = w.Write(bs)
+	return err
+}
@@ -0,0 +1,44 @@
+package renderer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type tagged struct {
+	Name    string `json:"name"`
+	Skipped string `json:"-"`
+	Empty   string `json:"empty,omitempty"`
+}
+
+func TestMsgPackCBOR(t *testing.T) {
+	v := tagged{Name: "goview", Skipped: "x"}
+
+	rec := httptest.NewRecorder()
+	if err := MsgPack(rec, http.StatusOK, v); err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 1 || m["name"] != "goview" || rec.Header().Get(ContentType) != ContentMsgPack {
+		t.Errorf("msgpack: %v %q", m, rec.Header().Get(ContentType))
+	}
+
+	rec = httptest.NewRecorder()
+	if err := CBOR(rec, http.StatusOK, v); err != nil {
+		t.Fatal(err)
+	}
+	m = nil
+	if err := cbor.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 1 || m["name"] != "goview" || rec.Header().Get(ContentType) != ContentCBOR {
+		t.Errorf("cbor: %v %q", m, rec.Header().Get(ContentType))
+	}
+}
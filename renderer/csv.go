@@ -0,0 +1,291 @@
+package renderer
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ContentCSV header value for CSV data.
+	ContentCSV = "text/csv; charset=utf-8"
+	// ContentTSV header value for TSV data.
+	ContentTSV = "text/tab-separated-values; charset=utf-8"
+)
+
+// CSVOptions struct
+type CSVOptions struct {
+	Filename  string   //download filename, such as "users.csv", sets Content-Disposition attachment
+	Delimiter rune     //field delimiter, default ',' for CSV and '\t' for TSV
+	BOM       bool     //write a UTF-8 byte order mark, for Excel
+	Columns   []string //selected columns in order, by header name; default all
+	NoHeader  bool     //do not write the header row
+}
+
+// CSV serve a slice of structs or maps, or an Iterator of them, as CSV response
+func CSV(w http.ResponseWriter, status int, v interface{}, opts CSVOptions) error {
+	return std.CSV(w, status, v, opts)
+}
+
+// TSV serve a slice of structs or maps, or an Iterator of them, as TSV response
+func TSV(w http.ResponseWriter, status int, v interface{}, opts CSVOptions) error {
+	return std.TSV(w, status, v, opts)
+}
+
+// CSV serve a slice of structs or maps, or an Iterator of them, as CSV response.
+//
+// The header row is made of the struct field `csv` tags, or `json` tags, or names;
+// fields tagged `csv:"-"` are skipped. Map keys are sorted unless Columns is set.
+// Rows are written and flushed while iterating.
+func (r *Renderer) CSV(w http.ResponseWriter, status int, v interface{}, opts CSVOptions) error {
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	return r.writeCSV(w, status, v, opts, ContentCSV)
+}
+
+// TSV serve a slice of structs or maps, or an Iterator of them, as TSV response, see CSV.
+func (r *Renderer) TSV(w http.ResponseWriter, status int, v interface{}, opts CSVOptions) error {
+	if opts.Delimiter == 0 {
+		opts.Delimiter = '\t'
+	}
+	return r.writeCSV(w, status, v, opts, ContentTSV)
+}
+
+func (r *Renderer) writeCSV(w http.ResponseWriter, status int, v interface{}, opts CSVOptions, contentType string) error {
+	next, ok := v.(Iterator)
+	var elemType reflect.Type
+	if !ok {
+		t := reflect.TypeOf(v)
+		if t == nil || t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return fmt.Errorf("renderer: csv needs a slice or an Iterator, got %T", v)
+		}
+		elemType = t.Elem()
+		next = FromSlice(v)
+	}
+
+	// the first item gives the columns of maps and iterators
+	first, err := next()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	empty := err == io.EOF
+	var cols *csvColumns
+	switch {
+	case !empty:
+		cols, err = newCSVColumns(nil, reflect.ValueOf(first), opts.Columns)
+	case elemType == nil || elemType.Kind() == reflect.Interface:
+		// nothing tells the row type, only the selected columns are known
+		cols, err = &csvColumns{names: opts.Columns}, nil
+	default:
+		cols, err = newCSVColumns(elemType, reflect.Value{}, opts.Columns)
+	}
+	if err != nil {
+		return err
+	}
+
+	setContentType(w, contentType)
+	if opts.Filename != "" {
+		w.Header().Set(ContentDisposition, contentDispositionValue(contentDispositionAttachment, opts.Filename))
+	}
+	w.WriteHeader(status)
+
+	if opts.BOM {
+		if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+			return err
+		}
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.Delimiter
+	if !opts.NoHeader && len(cols.names) > 0 {
+		if err := cw.Write(cols.names); err != nil {
+			return err
+		}
+	}
+
+	f := newFlusher(w, r.Options().FlushEvery)
+	f.buffer = cw.Flush
+	for item := first; !empty; {
+		record, err := cols.record(reflect.ValueOf(item))
+		if err != nil {
+			return err
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+		f.item()
+
+		item, err = next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cw.Flush()
+			return err
+		}
+	}
+	f.flush()
+	return cw.Error()
+}
+
+// csvColumns extracts the selected columns of struct or map rows.
+type csvColumns struct {
+	names  []string
+	fields [][]int //struct field indexes, nil for maps
+}
+
+func newCSVColumns(t reflect.Type, first reflect.Value, selected []string) (*csvColumns, error) {
+	if first.IsValid() {
+		t = first.Type()
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil, fmt.Errorf("renderer: csv needs struct or map rows")
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		cols := &csvColumns{}
+		names, fields := structColumns(t, nil)
+		if selected == nil {
+			cols.names, cols.fields = names, fields
+			return cols, nil
+		}
+		for _, name := range selected {
+			i := indexOf(names, name)
+			if i < 0 {
+				return nil, fmt.Errorf("renderer: csv column %q not found in %v", name, t)
+			}
+			cols.names = append(cols.names, name)
+			cols.fields = append(cols.fields, fields[i])
+		}
+		return cols, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("renderer: csv needs map rows with string keys, got %v", t)
+		}
+		if selected != nil {
+			return &csvColumns{names: selected}, nil
+		}
+		cols := &csvColumns{}
+		for first.Kind() == reflect.Ptr || first.Kind() == reflect.Interface {
+			first = first.Elem()
+		}
+		if first.IsValid() {
+			for _, key := range first.MapKeys() {
+				cols.names = append(cols.names, key.String())
+			}
+			sort.Strings(cols.names)
+		}
+		return cols, nil
+	}
+	return nil, fmt.Errorf("renderer: csv needs struct or map rows, got %v", t)
+}
+
+func structColumns(t reflect.Type, index []int) (names []string, fields [][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int{}, index...), i)
+		tag := sf.Tag.Get("csv")
+		if tag == "" {
+			tag = sf.Tag.Get("json")
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				n, f := structColumns(ft, idx)
+				names, fields = append(names, n...), append(fields, f...)
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		names = append(names, name)
+		fields = append(fields, idx)
+	}
+	return names, fields
+}
+
+func (c *csvColumns) record(v reflect.Value) ([]string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return make([]string, len(c.names)), nil
+		}
+		v = v.Elem()
+	}
+	record := make([]string, len(c.names))
+	for i, name := range c.names {
+		var field reflect.Value
+		switch v.Kind() {
+		case reflect.Struct:
+			if c.fields == nil {
+				return nil, fmt.Errorf("renderer: csv rows must have the same type, got %v", v.Type())
+			}
+			var err error
+			if field, err = v.FieldByIndexErr(c.fields[i]); err != nil {
+				continue //nil embedded pointer
+			}
+		case reflect.Map:
+			field = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		default:
+			return nil, fmt.Errorf("renderer: csv needs struct or map rows, got %v", v.Type())
+		}
+		record[i] = csvValue(field)
+	}
+	return record, nil
+}
+
+func csvValue(v reflect.Value) string {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	switch x := v.Interface().(type) {
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case time.Time:
+		return x.Format(time.RFC3339)
+	case encoding.TextMarshaler:
+		if b, err := x.MarshalText(); err == nil {
+			return string(b)
+		}
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
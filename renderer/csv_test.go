@@ -0,0 +1,121 @@
+package renderer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type base struct {
+	ID int `csv:"id"`
+}
+
+type user struct {
+	base
+	Name    string    `json:"name"`
+	Email   string    `csv:"email"`
+	Secret  string    `csv:"-"`
+	Created time.Time `csv:"created"`
+	private string
+}
+
+func TestCSV(t *testing.T) {
+	created := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	users := []user{
+		{base{1}, "Ann", "ann@example.com", "x", created, ""},
+		{base{2}, "Bob, Jr.", "bob@example.com", "y", created, ""},
+	}
+
+	rec := httptest.NewRecorder()
+	if err := CSV(rec, http.StatusOK, users, CSVOptions{Filename: "users ü.csv"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,name,email,created\n" +
+		"1,Ann,ann@example.com,2019-06-01T12:00:00Z\n" +
+		"2,\"Bob, Jr.\",bob@example.com,2019-06-01T12:00:00Z\n"
+	if rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+	if cd := rec.Header().Get(ContentDisposition); cd != `attachment; filename="users _.csv"; filename*=UTF-8''users%20%C3%BC.csv` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := TSV(rec, http.StatusOK, FromSlice(users), CSVOptions{Columns: []string{"email", "id"}, BOM: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\uFEFFemail\tid\nann@example.com\t1\nbob@example.com\t2\n"; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+	if rec.Header().Get(ContentType) != ContentTSV {
+		t.Errorf("Content-Type = %q", rec.Header().Get(ContentType))
+	}
+
+	if err := CSV(httptest.NewRecorder(), http.StatusOK, users, CSVOptions{Columns: []string{"nope"}}); err == nil {
+		t.Error("expected error for an unknown column")
+	}
+}
+
+func TestCSVMaps(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"b": 2, "a": "x"},
+		{"a": "y"},
+	}
+	rec := httptest.NewRecorder()
+	if err := CSV(rec, http.StatusOK, rows, CSVOptions{Delimiter: ';'}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a;b\nx;2\ny;\n"; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := CSV(rec, http.StatusOK, []user{}, CSVOptions{Columns: []string{"name"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "name\n"; rec.Body.String() != want {
+		t.Errorf("empty: got %q, want %q", rec.Body.String(), want)
+	}
+
+	for _, rows := range []interface{}{[]interface{}{}, FromSlice([]interface{}{})} {
+		rec = httptest.NewRecorder()
+		if err := CSV(rec, http.StatusOK, rows, CSVOptions{Columns: []string{"a", "b"}}); err != nil {
+			t.Fatal(err)
+		}
+		if want := "a,b\n"; rec.Body.String() != want {
+			t.Errorf("empty %T: got %q, want %q", rows, rec.Body.String(), want)
+		}
+
+		rec = httptest.NewRecorder()
+		if err := CSV(rec, http.StatusOK, rows, CSVOptions{Columns: []string{"a", "b"}, NoHeader: true}); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+			t.Errorf("empty %T without header: got %d %q", rows, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// flushRecorder records the body written at each Flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed []string
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushed = append(f.flushed, f.Body.String())
+	f.ResponseRecorder.Flush()
+}
+
+func TestCSVFlushEvery(t *testing.T) {
+	rows := []base{{1}, {2}, {3}}
+	rec := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	if err := New(Options{FlushEvery: 2}).CSV(rec, http.StatusOK, rows, CSVOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"id\n1\n2\n", "id\n1\n2\n3\n"}
+	if len(rec.flushed) != len(want) || rec.flushed[0] != want[0] || rec.flushed[1] != want[1] {
+		t.Errorf("flushed %q, want %q", rec.flushed, want)
+	}
+}
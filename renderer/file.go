@@ -0,0 +1,254 @@
+package renderer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServeFile serve a file from disk with Range requests, If-Modified-Since, If-None-Match and Content-Length.
+func ServeFile(w http.ResponseWriter, req *http.Request, fpath, name string, inline bool) error {
+	return std.ServeFile(w, req, fpath, name, inline)
+}
+
+// ServeFS serve a file of fsys, such as embed.FS, see ServeFile.
+func ServeFS(w http.ResponseWriter, req *http.Request, fsys fs.FS, fpath, name string, inline bool) error {
+	return std.ServeFS(w, req, fsys, fpath, name, inline)
+}
+
+// ServeContent serve content from an io.ReadSeeker, see ServeFile.
+func ServeContent(w http.ResponseWriter, req *http.Request, content io.ReadSeeker, name string, modtime time.Time, inline bool) error {
+	return std.ServeContent(w, req, content, name, modtime, inline)
+}
+
+// Binary serve file as application/octet-stream response; you may add ContentDisposition by your own.
+// The reader is streamed, Content-Length is set when it is an io.Seeker. An io.ReadSeeker with
+// status 200 is served from its start by ServeContent, which answers no Range or conditional
+// requests without the request; call ServeContent for them.
+func (r *Renderer) Binary(w http.ResponseWriter, status int, reader io.Reader, filename string, inline bool) error {
+	setContentType(w, ContentBinary)
+	if content, ok := reader.(io.ReadSeeker); ok && status == http.StatusOK {
+		return r.ServeContent(w, noRequest(), content, filename, time.Time{}, inline)
+	}
+	setDisposition(w, filename, inline)
+	setContentLength(w, reader)
+	w.WriteHeader(status)
+	_, err := io.Copy(w, reader)
+	return err
+}
+
+// File serve file as response from io.Reader, the content type is sniffed from the first bytes.
+// The reader is streamed, Content-Length is set when it is an io.Seeker. An io.ReadSeeker with
+// status 200 is served from its start by ServeContent, see Binary.
+func (r *Renderer) File(w http.ResponseWriter, status int, reader io.Reader, filename string, inline bool) error {
+	if content, ok := reader.(io.ReadSeeker); ok && status == http.StatusOK {
+		ctype, err := sniff(content)
+		if err != nil {
+			return err
+		}
+		setContentType(w, ctype)
+		return r.ServeContent(w, noRequest(), content, filename, time.Time{}, inline)
+	}
+	setContentLength(w, reader)
+	br := bufio.NewReaderSize(reader, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+
+	// set headers
+	setDisposition(w, filename, inline)
+	setContentType(w, http.DetectContentType(head))
+	w.WriteHeader(status)
+
+	_, err = io.Copy(w, br)
+	return err
+}
+
+// FileView serve file as response with content-disposition value inline, see File.
+//
+// Deprecated: use ServeFile, which answers Range and conditional requests.
+func (r *Renderer) FileView(w http.ResponseWriter, status int, fpath, name string) error {
+	return r.file(w, status, fpath, name, true)
+}
+
+// FileDownload serve file as response with content-disposition value attachment, see File.
+//
+// Deprecated: use ServeFile, which answers Range and conditional requests.
+func (r *Renderer) FileDownload(w http.ResponseWriter, status int, fpath, name string) error {
+	return r.file(w, status, fpath, name, false)
+}
+
+func (r *Renderer) file(w http.ResponseWriter, status int, fpath, name string, inline bool) error {
+	real, err := r.resolvePath(fpath)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(real)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.File(w, status, f, fileName(fpath, name), inline)
+}
+
+// ServeFile serve a file from disk with Range requests, If-Modified-Since, If-None-Match and Content-Length.
+// name is the filename of Content-Disposition, the base of fpath by default. With Options.FileRoot,
+// fpath is relative to the root and can not escape it.
+func (r *Renderer) ServeFile(w http.ResponseWriter, req *http.Request, fpath, name string, inline bool) error {
+	real, err := r.resolvePath(fpath)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(real)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("renderer: %s is a directory", fpath)
+	}
+	setETag(w, info)
+	return r.ServeContent(w, req, f, fileName(fpath, name), info.ModTime(), inline)
+}
+
+// ServeFS serve a file of fsys, such as embed.FS, see ServeFile.
+func (r *Renderer) ServeFS(w http.ResponseWriter, req *http.Request, fsys fs.FS, fpath, name string, inline bool) error {
+	f, err := fsys.Open(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("renderer: %s is a directory", fpath)
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		bs, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(bs)
+	}
+	if !info.ModTime().IsZero() {
+		setETag(w, info)
+	}
+	return r.ServeContent(w, req, content, fileName(fpath, name), info.ModTime(), inline)
+}
+
+// ServeContent serve content from an io.ReadSeeker with Range requests, conditional requests
+// and Content-Length. The content type is set from the name extension or sniffed, an ETag
+// header set beforehand is used for If-None-Match. A read or seek error of content is
+// returned, the headers may already be written.
+func (r *Renderer) ServeContent(w http.ResponseWriter, req *http.Request, content io.ReadSeeker, name string, modtime time.Time, inline bool) error {
+	setDisposition(w, name, inline)
+	nosniff(w)
+	rs := &errReadSeeker{ReadSeeker: content}
+	http.ServeContent(w, req, name, modtime, rs)
+	return rs.err
+}
+
+// noRequest is a plain GET for ServeContent when the request is unknown.
+func noRequest() *http.Request {
+	return &http.Request{Method: http.MethodGet, Header: http.Header{}}
+}
+
+// sniff detects the content type of the first bytes of content and seeks back.
+func sniff(content io.ReadSeeker) (string, error) {
+	cur, err := content.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := content.Seek(cur, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// errReadSeeker records the first error of a ReadSeeker, http.ServeContent does not return it.
+type errReadSeeker struct {
+	io.ReadSeeker
+	err error
+}
+
+func (e *errReadSeeker) Read(p []byte) (int, error) {
+	n, err := e.ReadSeeker.Read(p)
+	if err != nil && err != io.EOF && e.err == nil {
+		e.err = err
+	}
+	return n, err
+}
+
+func (e *errReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	n, err := e.ReadSeeker.Seek(offset, whence)
+	if err != nil && e.err == nil {
+		e.err = err
+	}
+	return n, err
+}
+
+// fileName returns name with the extension of fpath, or the base of fpath.
+func fileName(fpath, name string) string {
+	if name == "" {
+		return filepath.Base(fpath)
+	}
+	if ext := filepath.Ext(fpath); !strings.HasSuffix(name, ext) {
+		return name + ext
+	}
+	return name
+}
+
+func setDisposition(w http.ResponseWriter, filename string, inline bool) {
+	disposition := contentDispositionAttachment
+	if inline {
+		disposition = contentDispositionInline
+	}
+	w.Header().Set(ContentDisposition, contentDispositionValue(disposition, filename))
+}
+
+// setContentLength sets Content-Length from the remaining size of a seekable reader.
+func setContentLength(w http.ResponseWriter, reader io.Reader) {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return
+	}
+	cur, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return
+	}
+	if _, err := seeker.Seek(cur, io.SeekStart); err != nil {
+		return
+	}
+	w.Header().Set(ContentLength, strconv.FormatInt(end-cur, 10))
+}
+
+func setETag(w http.ResponseWriter, info fs.FileInfo) {
+	if w.Header().Get("ETag") == "" {
+		w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	}
+}
@@ -0,0 +1,135 @@
+package renderer
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFileDownload(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(fpath, []byte("hello file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := FileDownload(rec, http.StatusOK, fpath, "monthly"); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Body.String() != "hello file" {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+	if rec.Header().Get(ContentLength) != "10" || rec.Header().Get(ContentDisposition) != `attachment; filename="monthly.txt"` {
+		t.Errorf("unexpected headers: %v", rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	if err := FileView(rec, http.StatusCreated, fpath, ""); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusCreated || rec.Body.String() != "hello file" || rec.Header().Get(ContentLength) != "10" {
+		t.Errorf("status: %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-4")
+	if err := ServeFile(rec, req, fpath, "", true); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "hello" {
+		t.Errorf("range: %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	if err := FileView(rec, http.StatusOK, filepath.Join(filepath.Dir(fpath), "missing.txt"), ""); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: err = %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := Binary(rec, http.StatusOK, strings.NewReader("bin"), "", true); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Body.String() != "bin" || rec.Header().Get(ContentLength) != "3" || rec.Header().Get(ContentType) != ContentBinary ||
+		rec.Header().Get("Accept-Ranges") != "bytes" {
+		t.Errorf("binary: %q %v", rec.Body.String(), rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	if err := File(rec, http.StatusOK, strings.NewReader("<html>page"), "page", true); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Body.String() != "<html>page" || rec.Header().Get(ContentType) != "text/html; charset=utf-8" ||
+		rec.Header().Get("Accept-Ranges") != "bytes" {
+		t.Errorf("file: %q %v", rec.Body.String(), rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	if err := Binary(rec, http.StatusOK, io.MultiReader(strings.NewReader("stream")), "", true); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Body.String() != "stream" || rec.Header().Get(ContentLength) != "" || rec.Header().Get("Accept-Ranges") != "" {
+		t.Errorf("stream: %q %v", rec.Body.String(), rec.Header())
+	}
+}
+
+// failingContent fails reading after the headers are written.
+type failingContent struct{ *strings.Reader }
+
+func (failingContent) Read([]byte) (int, error) { return 0, errors.New("disk error") }
+
+func TestServeContentError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	content := failingContent{strings.NewReader("some content")}
+	if err := ServeContent(rec, req, content, "a.txt", time.Time{}, true); err == nil || err.Error() != "disk error" {
+		t.Errorf("err = %v, want disk error", err)
+	}
+}
+
+func TestServeFS(t *testing.T) {
+	modTime := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{"docs/a.txt": {Data: []byte("0123456789"), ModTime: modTime}}
+
+	req := httptest.NewRequest("GET", "/a.txt", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	rec := httptest.NewRecorder()
+	if err := ServeFS(rec, req, fsys, "docs/a.txt", "", true); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "2345" {
+		t.Errorf("range: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get(ContentDisposition) != `inline; filename="a.txt"` || rec.Header().Get(ContentType) != "text/plain; charset=utf-8" {
+		t.Errorf("unexpected headers: %v", rec.Header())
+	}
+	etag := rec.Header().Get("ETag")
+
+	req = httptest.NewRequest("GET", "/a.txt", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	if err := ServeFS(rec, req, fsys, "docs/a.txt", "", true); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: code = %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/a.txt", nil)
+	req.Header.Set("If-Modified-Since", modTime.Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	if err := ServeContent(rec, req, strings.NewReader("x"), "a.txt", modTime, false); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since: code = %d", rec.Code)
+	}
+}
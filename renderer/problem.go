@@ -0,0 +1,238 @@
+package renderer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+const (
+	// ContentProblemJSON header value for RFC 7807 problem details as JSON.
+	ContentProblemJSON = "application/problem+json"
+	// ContentProblemXML header value for RFC 7807 problem details as XML.
+	ContentProblemXML = "application/problem+xml"
+	// problemNamespace is the XML namespace of RFC 7807.
+	problemNamespace = "urn:ietf:rfc:7807"
+)
+
+// Problem is an RFC 7807 problem details object. Extensions are additional
+// members serialized next to the standard ones.
+type Problem struct {
+	Type       string                 //uri reference of the problem type, "about:blank" when empty
+	Title      string                 //short summary of the problem type
+	Status     int                    //http status code
+	Detail     string                 //explanation of this occurrence
+	Instance   string                 //uri reference of this occurrence
+	Extensions map[string]interface{} //extension members
+}
+
+// NewProblem creates a problem with the status text as title.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// ProblemFromError converts an error to a problem. A *Problem in the chain is
+// returned as is; an error with a `Status() int` method, such as goview.StatusError,
+// gives the status, other errors are 500. The error text is only used as detail
+// for client errors (4xx), server error messages are not exposed.
+func ProblemFromError(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+	status := http.StatusInternalServerError
+	var se interface {
+		error
+		Status() int
+	}
+	if errors.As(err, &se) && se.Status() >= 400 {
+		status = se.Status()
+	}
+	detail := ""
+	if status < 500 {
+		detail = err.Error()
+	}
+	return NewProblem(status, detail)
+}
+
+// Error method
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("%d %s: %s", p.Status, p.Title, p.Detail)
+	}
+	return fmt.Sprintf("%d %s", p.Status, p.Title)
+}
+
+// MarshalJSON method
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	for k, v := range p.members() {
+		m[k] = v
+	}
+	return json.Marshal(m)
+}
+
+// MarshalXML method, as in appendix A of RFC 7807
+func (p *Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Space: problemNamespace, Local: "problem"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	members := p.members()
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		if v, ok := members[k]; ok {
+			if err := e.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+				return err
+			}
+		}
+	}
+	keys := make([]string, 0, len(p.Extensions))
+	for k := range p.Extensions {
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := encodeXMLValue(e, k, p.Extensions[k]); err != nil {
+			return err
+		}
+	}
+	if err := e.EncodeToken(start.End()); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// members returns the standard members that are set.
+func (p *Problem) members() map[string]interface{} {
+	m := make(map[string]interface{}, 5)
+	if p.Type != "" {
+		m["type"] = p.Type
+	}
+	if p.Title != "" {
+		m["title"] = p.Title
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	return m
+}
+
+// encodeXMLValue encodes slices as <i> items and maps as nested elements.
+func encodeXMLValue(e *xml.Encoder, name string, v interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := encodeXMLValue(e, "i", rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := encodeXMLValue(e, k, rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface()); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	}
+	return e.EncodeElement(v, start)
+}
+
+// ProblemJSON serve a problem as application/problem+json response
+func ProblemJSON(w http.ResponseWriter, p *Problem) error {
+	return std.ProblemJSON(w, p)
+}
+
+// ProblemXML serve a problem as application/problem+xml response
+func ProblemXML(w http.ResponseWriter, p *Problem) error {
+	return std.ProblemXML(w, p)
+}
+
+// ProblemError serve an error as application/problem+json response, see ProblemFromError.
+func ProblemError(w http.ResponseWriter, err error) error {
+	return std.ProblemError(w, err)
+}
+
+// ProblemJSON serve a problem as application/problem+json response with its status, 500 if not set.
+func (r *Renderer) ProblemJSON(w http.ResponseWriter, p *Problem) error {
+	bs, err := fjson(p, r.Options())
+	if err != nil {
+		return err
+	}
+	setContentType(w, ContentProblemJSON)
+	w.WriteHeader(problemStatus(p))
+	_, err = w.Write(bs)
+	return err
+}
+
+// ProblemXML serve a problem as application/problem+xml response with its status, 500 if not set.
+func (r *Renderer) ProblemXML(w http.ResponseWriter, p *Problem) error {
+	opts := r.Options()
+	var bs []byte
+	var err error
+	if opts.XMLIndent {
+		bs, err = xml.MarshalIndent(p, "", " ")
+	} else {
+		bs, err = xml.Marshal(p)
+	}
+	if err != nil {
+		return err
+	}
+	setContentType(w, ContentProblemXML)
+	w.WriteHeader(problemStatus(p))
+	if opts.XMLPrefix != "" {
+		w.Write([]byte(opts.XMLPrefix))
+	}
+	_, err = w.Write(bs)
+	return err
+}
+
+// ProblemError serve an error as application/problem+json response, see ProblemFromError.
+func (r *Renderer) ProblemError(w http.ResponseWriter, err error) error {
+	return r.ProblemJSON(w, ProblemFromError(err))
+}
+
+func problemStatus(p *Problem) int {
+	if p.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return p.Status
+}
@@ -0,0 +1,70 @@
+package renderer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type statusError struct {
+	code int
+	err  error
+}
+
+func (se statusError) Error() string { return se.err.Error() }
+func (se statusError) Status() int   { return se.code }
+
+func TestProblem(t *testing.T) {
+	p := NewProblem(http.StatusForbidden, "Your current balance is 30, but that costs 50.")
+	p.Type = "https://example.com/probs/out-of-credit"
+	p.Instance = "/account/12345/msgs/abc"
+	p.Extensions = map[string]interface{}{"balance": 30, "accounts": []string{"/account/12345", "/account/67890"}}
+
+	rec := httptest.NewRecorder()
+	if err := ProblemJSON(rec, p); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"accounts":["/account/12345","/account/67890"],"balance":30,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc","status":403,"title":"Forbidden","type":"https://example.com/probs/out-of-credit"}`
+	if rec.Code != http.StatusForbidden || rec.Body.String() != want || rec.Header().Get(ContentType) != ContentProblemJSON {
+		t.Errorf("json: %d %q\nwant %q", rec.Code, rec.Body.String(), want)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := ProblemXML(rec, p); err != nil {
+		t.Fatal(err)
+	}
+	want = `<problem xmlns="urn:ietf:rfc:7807"><type>https://example.com/probs/out-of-credit</type><title>Forbidden</title><status>403</status><detail>Your current balance is 30, but that costs 50.</detail><instance>/account/12345/msgs/abc</instance><accounts><i>/account/12345</i><i>/account/67890</i></accounts><balance>30</balance></problem>`
+	if rec.Body.String() != want || rec.Header().Get(ContentType) != ContentProblemXML {
+		t.Errorf("xml: %q\nwant %q", rec.Body.String(), want)
+	}
+}
+
+func TestProblemFromError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		detail string
+	}{
+		{statusError{http.StatusNotFound, errors.New("post 42 not found")}, http.StatusNotFound, "post 42 not found"},
+		{fmt.Errorf("wrapped: %w", statusError{http.StatusBadRequest, errors.New("bad id")}), http.StatusBadRequest, "wrapped: bad id"},
+		{&statusError{http.StatusInternalServerError, errors.New("template /srv/views/x.html")}, http.StatusInternalServerError, ""},
+		{errors.New("db down"), http.StatusInternalServerError, ""},
+		{NewProblem(http.StatusConflict, "exists"), http.StatusConflict, "exists"},
+	}
+	for _, tt := range tests {
+		p := ProblemFromError(tt.err)
+		if p.Status != tt.status || p.Detail != tt.detail || p.Title != http.StatusText(tt.status) {
+			t.Errorf("%v: got %+v", tt.err, p)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	if err := ProblemError(rec, statusError{http.StatusNotFound, errors.New("nope")}); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotFound || rec.Body.String() != `{"detail":"nope","status":404,"title":"Not Found"}` {
+		t.Errorf("got %d %q", rec.Code, rec.Body.String())
+	}
+}
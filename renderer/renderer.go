@@ -6,14 +6,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"encoding/xml"
-	"errors"
-	"fmt"
 	"html/template"
 	"io"
-	"io/ioutil"
 	"net/http"
-	"path/filepath"
-	"strings"
 
 	yaml "gopkg.in/yaml.v2"
 )
@@ -27,6 +22,8 @@ const (
 	ContentHTML = "text/html"
 	// ContentJSON header value for JSON data.
 	ContentJSON = "application/json"
+	// ContentNDJSON header value for newline delimited JSON data.
+	ContentNDJSON = "application/x-ndjson"
 	// ContentJSONP header value for JSONP data.
 	ContentJSONP = "application/javascript"
 	// ContentLength header constant.
@@ -39,6 +36,10 @@ const (
 	ContentXML = "text/xml"
 	// ContentYAML represents content type application/x-yaml
 	ContentYAML = "application/x-yaml"
+	// ContentMsgPack header value for MessagePack data.
+	ContentMsgPack = "application/x-msgpack"
+	// ContentCBOR header value for CBOR data.
+	ContentCBOR = "application/cbor"
 	// ContentOctet describes octet-stream
 	ContentOctet = "application/octet-stream"
 	// ContentDisposition describes contentDisposition
@@ -49,7 +50,27 @@ const (
 	contentDispositionAttachment string = "attachment"
 )
 
+// Options struct
+type Options struct {
+	JSONPrefix   string `yaml:"jsonprefix"`   //prefix written before JSON, such as ")]}',\n"
+	JSONIndent   bool   `yaml:"jsonindent"`   //indent JSON
+	XMLPrefix    string `yaml:"xmlprefix"`    //prefix written before XML, such as xml.Header
+	XMLIndent    bool   `yaml:"xmlindent"`    //indent XML
+	UnEscapeHTML bool   `yaml:"unescapehtml"` //do not escape <, > and & in JSON
+	FlushEvery   int    `yaml:"flushevery"`   //flush streams every n items, default 1
+	FileRoot     string `yaml:"fileroot"`     //confine FileView, FileDownload and ServeFile to this directory
+}
+
+// Renderer struct
+type Renderer struct {
+	opts   Options
+	legacy bool //use the package level vars, for the default instance
+}
+
 // Vars
+//
+// Deprecated: the package level vars are only used by the package functions,
+// they are not safe to change at runtime. Use New(Options{...}) instead.
 var (
 	JSONPrefix   string
 	JSONIndent   bool
@@ -58,58 +79,151 @@ var (
 	UnEscapeHTML bool
 )
 
+var std = &Renderer{legacy: true}
+
+// New function
+func New(opts Options) *Renderer {
+	return &Renderer{opts: opts}
+}
+
+// Default returns the instance used by the package functions.
+func Default() *Renderer {
+	return std
+}
+
+// Options returns the options of the renderer.
+func (r *Renderer) Options() Options {
+	if r.legacy {
+		return Options{
+			JSONPrefix:   JSONPrefix,
+			JSONIndent:   JSONIndent,
+			XMLPrefix:    XMLPrefix,
+			XMLIndent:    XMLIndent,
+			UnEscapeHTML: UnEscapeHTML,
+		}
+	}
+	return r.opts
+}
+
 // NoContent serve success but no content response
 func NoContent(w http.ResponseWriter) error {
+	return std.NoContent(w)
+}
+
+// Raw render serve raw response where you have to build the headers, body
+func Raw(w http.ResponseWriter, status int, v interface{}) error {
+	return std.Raw(w, status, v)
+}
+
+// String serve string content as text/plain response
+func String(w http.ResponseWriter, status int, v interface{}) error {
+	return std.String(w, status, v)
+}
+
+// JSON serve data as JSON as response
+func JSON(w http.ResponseWriter, status int, v interface{}) error {
+	return std.JSON(w, status, v)
+}
+
+// JSONP serve data as JSONP response
+func JSONP(w http.ResponseWriter, status int, callback string, v interface{}) error {
+	return std.JSONP(w, status, callback, v)
+}
+
+// XML serve data as XML response
+func XML(w http.ResponseWriter, status int, v interface{}) error {
+	return std.XML(w, status, v)
+}
+
+// YAML serve data as YAML response
+func YAML(w http.ResponseWriter, status int, v interface{}) error {
+	return std.YAML(w, status, v)
+}
+
+// Binary serve file as application/octet-stream response; you may add ContentDisposition by your own.
+func Binary(w http.ResponseWriter, status int, reader io.Reader, filename string, inline bool) error {
+	return std.Binary(w, status, reader, filename, inline)
+}
+
+// File serve file as response from io.Reader
+func File(w http.ResponseWriter, status int, reader io.Reader, filename string, inline bool) error {
+	return std.File(w, status, reader, filename, inline)
+}
+
+// FileView serve file as response with content-disposition value inline
+//
+// Deprecated: use ServeFile, which answers Range and conditional requests.
+func FileView(w http.ResponseWriter, status int, fpath, name string) error {
+	return std.FileView(w, status, fpath, name)
+}
+
+// FileDownload serve file as response with content-disposition value attachment
+//
+// Deprecated: use ServeFile, which answers Range and conditional requests.
+func FileDownload(w http.ResponseWriter, status int, fpath, name string) error {
+	return std.FileDownload(w, status, fpath, name)
+}
+
+// HTMLString render string as html. Note: You must provide trusted html when using this method
+func HTMLString(w http.ResponseWriter, status int, html string) error {
+	return std.HTMLString(w, status, html)
+}
+
+// NoContent serve success but no content response
+func (r *Renderer) NoContent(w http.ResponseWriter) error {
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
 
 // Raw render serve raw response where you have to build the headers, body
-func Raw(w http.ResponseWriter, status int, v interface{}) error {
+func (r *Renderer) Raw(w http.ResponseWriter, status int, v interface{}) error {
+	nosniff(w)
 	w.WriteHeader(status)
 	_, err := w.Write(v.([]byte))
 	return err
 }
 
 // String serve string content as text/plain response
-func String(w http.ResponseWriter, status int, v interface{}) error {
-	w.Header().Set(ContentType, ContentText)
+func (r *Renderer) String(w http.ResponseWriter, status int, v interface{}) error {
+	setContentType(w, ContentText)
 	w.WriteHeader(status)
 	_, err := w.Write([]byte(v.(string)))
 	return err
 }
 
 // JSON serve data as JSON as response
-func JSON(w http.ResponseWriter, status int, v interface{}) error {
-	w.Header().Set(ContentType, ContentJSON)
-	w.WriteHeader(status)
-
-	bs, err := fjson(v)
+func (r *Renderer) JSON(w http.ResponseWriter, status int, v interface{}) error {
+	opts := r.Options()
+	bs, err := fjson(v, opts)
 	if err != nil {
 		return err
 	}
-	if JSONPrefix != "" {
-		w.Write([]byte(JSONPrefix))
+
+	setContentType(w, ContentJSON)
+	w.WriteHeader(status)
+	if opts.JSONPrefix != "" {
+		w.Write([]byte(opts.JSONPrefix))
 	}
 	_, err = w.Write(bs)
 	return err
 }
 
-// JSONP serve data as JSONP response
-func JSONP(w http.ResponseWriter, status int, callback string, v interface{}) error {
-	w.Header().Set(ContentType, ContentJSONP)
-	w.WriteHeader(status)
-
-	bs, err := fjson(v)
+// JSONP serve data as JSONP response, the callback must be a javascript identifier
+// such as `cb` or `app.handlers[0]`, otherwise ErrInvalidCallback is returned.
+func (r *Renderer) JSONP(w http.ResponseWriter, status int, callback string, v interface{}) error {
+	if !ValidCallback(callback) {
+		return ErrInvalidCallback
+	}
+	bs, err := fjson(v, r.Options())
 	if err != nil {
 		return err
 	}
 
-	if callback == "" {
-		return errors.New("renderer: callback can not bet empty")
-	}
+	setContentType(w, ContentJSONP)
+	w.WriteHeader(status)
 
-	w.Write([]byte(callback + "("))
+	// the leading comment prevents the response from being read as another content type
+	w.Write([]byte("/**/" + callback + "("))
 	_, err = w.Write(bs)
 	w.Write([]byte(");"))
 
@@ -117,13 +231,12 @@ func JSONP(w http.ResponseWriter, status int, callback string, v interface{}) er
 }
 
 // XML serve data as XML response
-func XML(w http.ResponseWriter, status int, v interface{}) error {
-	w.Header().Set(ContentType, ContentXML)
-	w.WriteHeader(status)
+func (r *Renderer) XML(w http.ResponseWriter, status int, v interface{}) error {
+	opts := r.Options()
 	var bs []byte
 	var err error
 
-	if XMLIndent {
+	if opts.XMLIndent {
 		bs, err = xml.MarshalIndent(v, "", " ")
 	} else {
 		bs, err = xml.Marshal(v)
@@ -132,96 +245,42 @@ func XML(w http.ResponseWriter, status int, v interface{}) error {
 		return err
 	}
 
-	if XMLPrefix != "" {
-		w.Write([]byte(XMLPrefix))
+	setContentType(w, ContentXML)
+	w.WriteHeader(status)
+	if opts.XMLPrefix != "" {
+		w.Write([]byte(opts.XMLPrefix))
 	}
 	_, err = w.Write(bs)
 	return err
 }
 
 // YAML serve data as YAML response
-func YAML(w http.ResponseWriter, status int, v interface{}) error {
-	w.Header().Set(ContentType, ContentYAML)
-	w.WriteHeader(status)
-
+func (r *Renderer) YAML(w http.ResponseWriter, status int, v interface{}) error {
 	bs, err := yaml.Marshal(v)
 	if err != nil {
 		return err
 	}
-	_, err = w.Write(bs)
-	return err
-}
-
-// Binary serve file as application/octet-stream response; you may add ContentDisposition by your own.
-func Binary(w http.ResponseWriter, status int, reader io.Reader, filename string, inline bool) error {
-	if inline {
-		w.Header().Set(ContentDisposition, fmt.Sprintf("%s; filename=%s", contentDispositionInline, filename))
-	} else {
-		w.Header().Set(ContentDisposition, fmt.Sprintf("%s; filename=%s", contentDispositionAttachment, filename))
-	}
-	w.Header().Set(ContentType, ContentBinary)
-	w.WriteHeader(status)
-	bs, err := ioutil.ReadAll(reader)
-	if err != nil {
-		return err
-	}
 
-	_, err = w.Write(bs)
-	return err
-}
-
-// File serve file as response from io.Reader
-func File(w http.ResponseWriter, status int, reader io.Reader, filename string, inline bool) error {
-	bs, err := ioutil.ReadAll(reader)
-	if err != nil {
-		return err
-	}
-
-	// set headers
-	mime := http.DetectContentType(bs)
-	if inline {
-		w.Header().Set(ContentDisposition, fmt.Sprintf("%s; filename=%s", contentDispositionInline, filename))
-	} else {
-		w.Header().Set(ContentDisposition, fmt.Sprintf("%s; filename=%s", contentDispositionAttachment, filename))
-	}
-	w.Header().Set(ContentType, mime)
+	setContentType(w, ContentYAML)
 	w.WriteHeader(status)
-
 	_, err = w.Write(bs)
 	return err
 }
 
-// FileView serve file as response with content-disposition value inline
-func FileView(w http.ResponseWriter, status int, fpath, name string) error {
-	return file(w, status, fpath, name, contentDispositionInline)
-}
-
-// FileDownload serve file as response with content-disposition value attachment
-func FileDownload(w http.ResponseWriter, status int, fpath, name string) error {
-	return file(w, status, fpath, name, contentDispositionAttachment)
-}
-
 // HTMLString render string as html. Note: You must provide trusted html when using this method
-func HTMLString(w http.ResponseWriter, status int, html string) error {
-	w.Header().Set(ContentType, ContentHTML)
+func (r *Renderer) HTMLString(w http.ResponseWriter, status int, html string) error {
+	setContentType(w, ContentHTML)
 	w.WriteHeader(status)
 	out := template.HTML(html)
 	_, err := w.Write([]byte(out))
 	return err
 }
 
-/*
-func MsgPack(w http.ResponseWriter, obj interface{}) error {
-	w.Header().Set(ContentType, ContentMsgPack)
-	.....
-}
-*/
-
 // json converts the data as bytes using json encoder
-func fjson(v interface{}) ([]byte, error) {
+func fjson(v interface{}, opts Options) ([]byte, error) {
 	var bs []byte
 	var err error
-	if JSONIndent {
+	if opts.JSONIndent {
 		bs, err = json.MarshalIndent(v, "", " ")
 	} else {
 		bs, err = json.Marshal(v)
@@ -229,48 +288,10 @@ func fjson(v interface{}) ([]byte, error) {
 	if err != nil {
 		return bs, err
 	}
-	if UnEscapeHTML {
+	if opts.UnEscapeHTML {
 		bs = bytes.Replace(bs, []byte("\\u003c"), []byte("<"), -1)
 		bs = bytes.Replace(bs, []byte("\\u003e"), []byte(">"), -1)
 		bs = bytes.Replace(bs, []byte("\\u0026"), []byte("&"), -1)
 	}
 	return bs, nil
 }
-
-// file serve file as response
-func file(w http.ResponseWriter, status int, fpath, name, contentDisposition string) error {
-	var bs []byte
-	var err error
-	bs, err = ioutil.ReadFile(fpath)
-	if err != nil {
-		return err
-	}
-	buf := bytes.NewBuffer(bs)
-
-	// filename, ext, mimes
-	var fn, mime, ext string
-	fn, err = filepath.Abs(fpath)
-	if err != nil {
-		return err
-	}
-	ext = filepath.Ext(fpath)
-	if name != "" {
-		if !strings.HasSuffix(name, ext) {
-			fn = name + ext
-		}
-	}
-
-	mime = http.DetectContentType(bs)
-
-	// set headers
-	w.Header().Set(ContentType, mime)
-	w.Header().Set(ContentDisposition, fmt.Sprintf("%s; filename=%s", contentDisposition, fn))
-	w.WriteHeader(status)
-
-	if _, err = buf.WriteTo(w); err != nil {
-		return err
-	}
-
-	_, err = w.Write(buf.Bytes())
-	return err
-}
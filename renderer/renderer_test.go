@@ -0,0 +1,62 @@
+package renderer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRendererOptions(t *testing.T) {
+	data := map[string]string{"a": "<b>"}
+
+	indented := New(Options{JSONPrefix: ")]}',\n", JSONIndent: true, UnEscapeHTML: true})
+	rec := httptest.NewRecorder()
+	if err := indented.JSON(rec, http.StatusOK, data); err != nil {
+		t.Fatal(err)
+	}
+	if want := ")]}',\n{\n \"a\": \"<b>\"\n}"; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+
+	// the package functions are not affected by other instances
+	rec = httptest.NewRecorder()
+	if err := JSON(rec, http.StatusOK, data); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":"\u003cb\u003e"}`; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+	if rec.Header().Get(ContentType) != ContentJSON {
+		t.Errorf("Content-Type = %q", rec.Header().Get(ContentType))
+	}
+
+	rec = httptest.NewRecorder()
+	if err := New(Options{XMLPrefix: "<?xml?>"}).XML(rec, http.StatusCreated, item{Name: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<?xml?><item><Name>x</Name></item>"; rec.Code != http.StatusCreated || rec.Body.String() != want {
+		t.Errorf("got %d %q, want %q", rec.Code, rec.Body.String(), want)
+	}
+}
+
+func TestMarshalErrorWritesNothing(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := XML(rec, http.StatusCreated, map[string]string{"a": "b"}); err == nil {
+		t.Fatal("expected an error marshaling a map as XML")
+	}
+	if rec.Code != http.StatusOK || rec.Header().Get(ContentType) != "" || rec.Body.Len() != 0 {
+		t.Errorf("got %d %q %q, want nothing written", rec.Code, rec.Header().Get(ContentType), rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	if err := JSON(rec, http.StatusCreated, func() {}); err == nil {
+		t.Fatal("expected an error marshaling a func as JSON")
+	}
+	if rec.Code != http.StatusOK || rec.Header().Get(ContentType) != "" || rec.Body.Len() != 0 {
+		t.Errorf("got %d %q %q, want nothing written", rec.Code, rec.Header().Get(ContentType), rec.Body.String())
+	}
+}
+
+type item struct {
+	Name string
+}
@@ -0,0 +1,114 @@
+package renderer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalidCallback is returned by JSONP for a callback that is not a javascript identifier.
+	ErrInvalidCallback = errors.New("renderer: invalid JSONP callback")
+	// ErrPathTraversal is returned by file responses for a path escaping Options.FileRoot.
+	ErrPathTraversal = errors.New("renderer: path escapes the file root")
+)
+
+// callbackPattern matches identifiers such as `cb`, `jQuery123_456` or `app.handlers[0]`.
+var callbackPattern = regexp.MustCompile(`^[A-Za-z_$][0-9A-Za-z_$]*(?:\.[A-Za-z_$][0-9A-Za-z_$]*|\[[0-9]+\])*$`)
+
+// maxCallbackLen limits the length of a JSONP callback.
+const maxCallbackLen = 128
+
+// ValidCallback reports whether a JSONP callback name is a safe javascript identifier path.
+func ValidCallback(callback string) bool {
+	return len(callback) <= maxCallbackLen && callbackPattern.MatchString(callback)
+}
+
+// setContentType sets the content type with `X-Content-Type-Options: nosniff`.
+func setContentType(w http.ResponseWriter, contentType string) {
+	w.Header().Set(ContentType, contentType)
+	nosniff(w)
+}
+
+func nosniff(w http.ResponseWriter) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+}
+
+// contentDispositionValue formats a Content-Disposition as RFC 6266: an ascii
+// `filename` fallback and, for other names, an RFC 5987 encoded `filename*`.
+func contentDispositionValue(disposition, filename string) string {
+	if filename == "" {
+		return disposition
+	}
+	fallback := make([]byte, 0, len(filename))
+	ascii := true
+	for i := 0; i < len(filename); i++ {
+		c := filename[i]
+		switch {
+		case c >= 0x80:
+			ascii = false
+			// one '_' per rune
+			if c >= 0xC0 {
+				fallback = append(fallback, '_')
+			}
+		case c < 0x20 || c == 0x7f || c == '"' || c == '\\' || c == '/':
+			ascii = false
+			fallback = append(fallback, '_')
+		default:
+			fallback = append(fallback, c)
+		}
+	}
+	value := fmt.Sprintf(`%s; filename="%s"`, disposition, fallback)
+	if !ascii {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+// encodeRFC5987 percent encodes all bytes but the attr-char of RFC 5987.
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+// resolvePath confines fpath to Options.FileRoot when it is set, fpath is then
+// relative to the root. Paths with `..` elements or symlinks leading out of the root
+// return ErrPathTraversal.
+func (r *Renderer) resolvePath(fpath string) (string, error) {
+	root := r.Options().FileRoot
+	if root == "" {
+		return fpath, nil
+	}
+	name := strings.TrimLeft(filepath.ToSlash(fpath), "/")
+	if !fs.ValidPath(name) || strings.Contains(name, "\\") {
+		return "", ErrPathTraversal
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	real, err := filepath.EvalSymlinks(filepath.Join(realRoot, filepath.FromSlash(name)))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(realRoot, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", ErrPathTraversal
+	}
+	return real, nil
+}
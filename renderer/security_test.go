@@ -0,0 +1,75 @@
+package renderer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONPCallback(t *testing.T) {
+	for _, cb := range []string{"cb", "jQuery123_456", "app.handlers[0]", "$"} {
+		rec := httptest.NewRecorder()
+		if err := JSONP(rec, http.StatusOK, cb, 1); err != nil {
+			t.Fatalf("%q: %v", cb, err)
+		}
+		if rec.Body.String() != "/**/"+cb+"(1);" || rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Errorf("%q: %q %v", cb, rec.Body.String(), rec.Header())
+		}
+	}
+	for _, cb := range []string{"", "alert(1)//", "cb;x", "<script>", "a..b", strings.Repeat("a", 200)} {
+		rec := httptest.NewRecorder()
+		if err := JSONP(rec, http.StatusOK, cb, 1); err != ErrInvalidCallback {
+			t.Errorf("%q: err = %v", cb, err)
+		}
+		if rec.Body.Len() != 0 || rec.Header().Get(ContentType) != "" {
+			t.Errorf("%q: response written", cb)
+		}
+	}
+}
+
+func TestContentDispositionValue(t *testing.T) {
+	for filename, want := range map[string]string{
+		"":           "inline",
+		"a.txt":      `inline; filename="a.txt"`,
+		`a"b\c.txt`:  `inline; filename="a_b_c.txt"; filename*=UTF-8''a%22b%5Cc.txt`,
+		"日本.txt":     `inline; filename="__.txt"; filename*=UTF-8''%E6%97%A5%E6%9C%AC.txt`,
+		"x\r\ny.txt": `inline; filename="x__y.txt"; filename*=UTF-8''x%0D%0Ay.txt`,
+	} {
+		if got := contentDispositionValue("inline", filename); got != want {
+			t.Errorf("%q: got %s, want %s", filename, got, want)
+		}
+	}
+}
+
+func TestFileRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "public")
+	os.Mkdir(root, 0755)
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("public"), 0644)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644)
+	if err := os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Skip(err)
+	}
+
+	r := New(Options{FileRoot: root})
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := r.FileView(rec, http.StatusOK, "/a.txt", ""); err != nil || rec.Body.String() != "public" {
+		t.Fatalf("FileView: %v %q", err, rec.Body.String())
+	}
+	for _, fpath := range []string{"../secret.txt", "sub/../../secret.txt", "link.txt", `..\secret.txt`} {
+		rec := httptest.NewRecorder()
+		if err := r.FileDownload(rec, http.StatusOK, fpath, ""); err != ErrPathTraversal {
+			t.Errorf("%q: err = %v", fpath, err)
+		}
+		if err := r.ServeFile(rec, req, fpath, "", false); err != ErrPathTraversal {
+			t.Errorf("ServeFile %q: err = %v", fpath, err)
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("%q: body written", fpath)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package renderer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentEventStream header value for Server-Sent Events.
+const ContentEventStream = "text/event-stream"
+
+// Event is a Server-Sent Event. Data of type string or []byte is sent as is,
+// other values are encoded as JSON.
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// TemplateRenderer renders a view, it is implemented by goview.ViewEngine.
+type TemplateRenderer interface {
+	RenderWriter(w io.Writer, name string, data interface{}) error
+}
+
+// SSE writes Server-Sent Events to a response, it is safe for concurrent use.
+type SSE struct {
+	w     http.ResponseWriter
+	f     http.Flusher
+	ctx   context.Context
+	opts  Options
+	mutex sync.Mutex
+}
+
+// NewSSE starts an event stream response with the default renderer.
+func NewSSE(w http.ResponseWriter, r *http.Request) (*SSE, error) {
+	return std.SSE(w, r)
+}
+
+// SSE starts an event stream response: it sets the headers, writes status 200 and flushes.
+func (r *Renderer) SSE(w http.ResponseWriter, req *http.Request) (*SSE, error) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("renderer: streaming unsupported, the response writer is not a http.Flusher")
+	}
+	header := w.Header()
+	setContentType(w, ContentEventStream)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+	return &SSE{w: w, f: f, ctx: req.Context(), opts: r.Options()}, nil
+}
+
+// Done is closed when the client disconnects.
+func (s *SSE) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send writes an event and flushes it.
+func (s *SSE) Send(e Event) error {
+	var data []byte
+	switch d := e.Data.(type) {
+	case nil:
+	case string:
+		data = []byte(d)
+	case []byte:
+		data = d
+	default:
+		var err error
+		if data, err = fjson(d, Options{UnEscapeHTML: s.opts.UnEscapeHTML}); err != nil {
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if e.ID != "" {
+		buf.WriteString("id: " + sseField(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		buf.WriteString("event: " + sseField(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(int64(e.Retry/time.Millisecond), 10) + "\n")
+	}
+	if e.Data != nil {
+		for _, line := range strings.Split(sseLines.Replace(string(data)), "\n") {
+			buf.WriteString("data: " + line + "\n")
+		}
+	}
+	buf.WriteString("\n")
+	return s.write(buf.Bytes())
+}
+
+// SendTemplate renders the view name with engine and sends the html as the event data.
+func (s *SSE) SendTemplate(engine TemplateRenderer, e Event, name string, data interface{}) error {
+	buf := new(bytes.Buffer)
+	if err := engine.RenderWriter(buf, name, data); err != nil {
+		return err
+	}
+	e.Data = buf.String()
+	return s.Send(e)
+}
+
+// Comment writes a comment line, clients ignore it; it keeps the connection alive.
+func (s *SSE) Comment(text string) error {
+	return s.write([]byte(": " + sseField(text) + "\n\n"))
+}
+
+// Run sends the events until the channel is closed or the client disconnects,
+// a heartbeat comment is sent when no event was sent for the heartbeat interval.
+func (s *SSE) Run(events <-chan Event, heartbeat time.Duration) error {
+	var tick <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := s.Send(e); err != nil {
+				return err
+			}
+		case <-tick:
+			if err := s.Comment("heartbeat"); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *SSE) write(b []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(b); err != nil {
+		return err
+	}
+	s.f.Flush()
+	return nil
+}
+
+// sseField removes the line breaks a single line field can not contain.
+// sseLines normalises the line endings of data, a lone \r ends a line too.
+var sseLines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+func sseField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,69 @@
+package renderer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testEngine struct{}
+
+func (testEngine) RenderWriter(w io.Writer, name string, data interface{}) error {
+	_, err := fmt.Fprintf(w, "<li>%s: %v</li>\n<li>end</li>", name, data)
+	return err
+}
+
+func TestSSE(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	sse, err := NewSSE(rec, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sse.Send(Event{ID: "1", Event: "up\ndate", Data: item{Name: "a"}, Retry: 2 * time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sse.SendTemplate(testEngine{}, Event{Event: "html"}, "item", 42); err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan Event, 1)
+	events <- Event{Data: "last"}
+	close(events)
+	if err := sse.Run(events, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "id: 1\nevent: update\nretry: 2000\ndata: {\"Name\":\"a\"}\n\n" +
+		"event: html\ndata: <li>item: 42</li>\ndata: <li>end</li>\n\n" +
+		"data: last\n\n"
+	if rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+	if rec.Header().Get(ContentType) != ContentEventStream || rec.Header().Get("Cache-Control") != "no-cache" ||
+		rec.Header().Get("Connection") != "" {
+		t.Errorf("unexpected headers: %v", rec.Header())
+	}
+
+	rec.Body.Reset()
+	if err := sse.Send(Event{Data: "a\rb\r\nc\nd"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "data: a\ndata: b\ndata: c\ndata: d\n\n"; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+
+	cancel()
+	if err := sse.Run(make(chan Event), time.Hour); err != context.Canceled {
+		t.Errorf("Run after disconnect = %v", err)
+	}
+	if err := sse.Send(Event{Data: "x"}); err == nil {
+		t.Error("expected error sending after disconnect")
+	}
+}
@@ -0,0 +1,193 @@
+package renderer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// Iterator yields the items of a stream, it returns io.EOF after the last item.
+type Iterator func() (interface{}, error)
+
+// FromChan returns an Iterator receiving from any channel type, until the channel is closed
+// or ctx is done. Pass the request context so a stream ends when the client goes away:
+//
+//	renderer.NDJSON(w, http.StatusOK, renderer.FromChan(r.Context(), ch))
+func FromChan(ctx context.Context, ch interface{}) Iterator {
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.RecvDir == 0 {
+		return func() (interface{}, error) {
+			return nil, errors.New("renderer: FromChan needs a receive channel")
+		}
+	}
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectRecv, Chan: v},
+	}
+	return func() (interface{}, error) {
+		// a done ctx wins over a ready channel
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chosen, item, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return nil, ctx.Err()
+		}
+		if !ok {
+			return nil, io.EOF
+		}
+		return item.Interface(), nil
+	}
+}
+
+// FromSlice returns an Iterator over any slice or array type.
+func FromSlice(slice interface{}) Iterator {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return func() (interface{}, error) {
+			return nil, errors.New("renderer: FromSlice needs a slice")
+		}
+	}
+	i := 0
+	return func() (interface{}, error) {
+		if i >= v.Len() {
+			return nil, io.EOF
+		}
+		i++
+		return v.Index(i - 1).Interface(), nil
+	}
+}
+
+// JSONStream serve data as JSON response, encoded directly to the response writer.
+func JSONStream(w http.ResponseWriter, status int, v interface{}) error {
+	return std.JSONStream(w, status, v)
+}
+
+// JSONArray serve the items of next as a JSON array, written and flushed while iterating.
+// An error of next, such as the ctx error of FromChan, ends the stream and is returned.
+func JSONArray(w http.ResponseWriter, status int, next Iterator) error {
+	return std.JSONArray(w, status, next)
+}
+
+// NDJSON serve the items of next as newline delimited JSON (JSON Lines), flushed while iterating.
+// An error of next, such as the ctx error of FromChan, ends the stream and is returned.
+func NDJSON(w http.ResponseWriter, status int, next Iterator) error {
+	return std.NDJSON(w, status, next)
+}
+
+// JSONStream serve data as JSON response, encoded directly to the response writer.
+func (r *Renderer) JSONStream(w http.ResponseWriter, status int, v interface{}) error {
+	opts := r.Options()
+	setContentType(w, ContentJSON)
+	w.WriteHeader(status)
+	if opts.JSONPrefix != "" {
+		if _, err := io.WriteString(w, opts.JSONPrefix); err != nil {
+			return err
+		}
+	}
+	return r.encoder(w, opts).Encode(v)
+}
+
+// JSONArray serve the items of next as a JSON array, written and flushed while iterating.
+func (r *Renderer) JSONArray(w http.ResponseWriter, status int, next Iterator) error {
+	opts := r.Options()
+	setContentType(w, ContentJSON)
+	w.WriteHeader(status)
+	if _, err := io.WriteString(w, opts.JSONPrefix+"["); err != nil {
+		return err
+	}
+	enc := r.encoder(w, opts)
+	f := newFlusher(w, opts.FlushEvery)
+	for n := 0; ; n++ {
+		item, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+		f.item()
+	}
+	_, err := io.WriteString(w, "]\n")
+	f.flush()
+	return err
+}
+
+// NDJSON serve the items of next as newline delimited JSON (JSON Lines), flushed while iterating.
+func (r *Renderer) NDJSON(w http.ResponseWriter, status int, next Iterator) error {
+	opts := r.Options()
+	setContentType(w, ContentNDJSON)
+	w.WriteHeader(status)
+	// one value per line, indent does not apply
+	opts.JSONIndent = false
+	enc := r.encoder(w, opts)
+	f := newFlusher(w, opts.FlushEvery)
+	for {
+		item, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+		f.item()
+	}
+	f.flush()
+	return nil
+}
+
+func (r *Renderer) encoder(w io.Writer, opts Options) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(!opts.UnEscapeHTML)
+	if opts.JSONIndent {
+		enc.SetIndent("", " ")
+	}
+	return enc
+}
+
+// flusher flushes the response writer every n items.
+type flusher struct {
+	f      http.Flusher
+	buffer func() //flushes a buffered writer, such as a csv.Writer, before the response
+	every  int
+	count  int
+}
+
+func newFlusher(w http.ResponseWriter, every int) *flusher {
+	f, _ := w.(http.Flusher)
+	if every <= 0 {
+		every = 1
+	}
+	return &flusher{f: f, every: every}
+}
+
+func (f *flusher) item() {
+	f.count++
+	if f.count >= f.every {
+		f.flush()
+	}
+}
+
+func (f *flusher) flush() {
+	f.count = 0
+	if f.buffer != nil {
+		f.buffer()
+	}
+	if f.f != nil {
+		f.f.Flush()
+	}
+}
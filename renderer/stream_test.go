@@ -0,0 +1,70 @@
+package renderer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNDJSON(t *testing.T) {
+	ch := make(chan item)
+	go func() {
+		defer close(ch)
+		for _, name := range []string{"a", "b", "c"} {
+			ch <- item{Name: name}
+		}
+	}()
+
+	rec := httptest.NewRecorder()
+	if err := New(Options{JSONIndent: true, FlushEvery: 2}).NDJSON(rec, http.StatusOK, FromChan(context.Background(), ch)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"Name\":\"a\"}\n{\"Name\":\"b\"}\n{\"Name\":\"c\"}\n"; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+	if rec.Header().Get(ContentType) != ContentNDJSON || !rec.Flushed {
+		t.Errorf("Content-Type = %q, flushed = %v", rec.Header().Get(ContentType), rec.Flushed)
+	}
+}
+
+func TestNDJSONContext(t *testing.T) {
+	ch := make(chan item, 1)
+	ch <- item{Name: "a"}
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	next := FromChan(req.Context(), ch)
+	stream := func() (interface{}, error) {
+		v, err := next()
+		// the client goes away after the first item, the channel is never closed
+		cancel()
+		return v, err
+	}
+
+	rec := httptest.NewRecorder()
+	if err := NDJSON(rec, http.StatusOK, stream); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if want := "{\"Name\":\"a\"}\n"; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+}
+
+func TestJSONArray(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := JSONArray(rec, http.StatusOK, FromSlice([]item{{"a"}, {"b"}})); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[{\"Name\":\"a\"}\n,{\"Name\":\"b\"}\n]\n"; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := JSONArray(rec, http.StatusOK, FromSlice([]item{})); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[]\n"; rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Package site pre-renders goview views to a directory of static files, such as a marketing site
+served from a CDN with the templates of the dynamic app.
+
+A site file lists the view config, the routes and the static directories, relative paths are
+relative to the site file:
+
+	view:
+	  root: views
+	  master: layouts/master
+	output: public
+	static:
+	  - dir: static
+	    prefix: static
+	routes:
+	  - path: /
+	    view: index
+	    data: data/index.yaml
+	  - path: /about/
+	    view: about.md
+	  - path: /sitemap.xml
+	    view: sitemap.xml.html
+
+Build it with `goview build -f site.yaml`, or in Go:
+
+	s, err := site.Load("site.yaml")
+	s.Routes = append(s.Routes, site.Route{Path: "/pricing/", View: "pricing", DataFunc: loadPlans})
+	files, err := s.Build()
+*/
+package site
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-tea/goview"
+	"github.com/go-tea/goview/markdown"
+	"gopkg.in/yaml.v2"
+)
+
+// Site generates static pages with a goview.ViewEngine.
+type Site struct {
+	Engine *goview.ViewEngine
+	Output string   //output directory
+	Routes []Route  //pages to render
+	Static []Static //directories copied as is
+}
+
+// Route is a page of the site.
+type Route struct {
+	Path     string                             `yaml:"path"` //url path, `/about/` is written to `about/index.html`, `/feed.xml` to `feed.xml`
+	View     string                             `yaml:"view"` //view name, as given to ViewEngine.Render
+	DataFile string                             `yaml:"data"` //JSON or YAML file of the render data
+	Data     interface{}                        `yaml:"-"`    //render data, overrides DataFile
+	DataFunc func(r Route) (interface{}, error) `yaml:"-"`    //callback returning the render data, overrides Data
+}
+
+// Static is a directory copied to the output.
+type Static struct {
+	Dir    string `yaml:"dir"`    //source directory
+	Prefix string `yaml:"prefix"` //destination under the output directory
+}
+
+// File is the content of a site file.
+type File struct {
+	View   goview.Config `yaml:"view"`
+	Output string        `yaml:"output"`
+	Static []Static      `yaml:"static"`
+	Routes []Route       `yaml:"routes"`
+}
+
+// Load reads a site file and creates its engine, the view config defaults to goview.DefaultConfig.
+func Load(file string) (*Site, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	f := File{View: goview.DefaultConfig, Output: "public"}
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("site: %s: %v", file, err)
+	}
+
+	dir := filepath.Dir(file)
+	f.View.Root = rel(dir, f.View.Root)
+	f.Output = rel(dir, f.Output)
+	for i := range f.Static {
+		f.Static[i].Dir = rel(dir, f.Static[i].Dir)
+	}
+	for i := range f.Routes {
+		if f.Routes[i].DataFile != "" {
+			f.Routes[i].DataFile = rel(dir, f.Routes[i].DataFile)
+		}
+	}
+	engine := goview.New(f.View)
+	if err := markdown.Use(engine); err != nil {
+		return nil, err
+	}
+	return &Site{
+		Engine: engine,
+		Output: f.Output,
+		Routes: f.Routes,
+		Static: f.Static,
+	}, nil
+}
+
+// Build renders the routes and copies the static directories, it returns the written files.
+// Existing files of the output directory are kept or overwritten.
+func (s *Site) Build() ([]string, error) {
+	var files []string
+	for _, r := range s.Routes {
+		file, err := s.render(r)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+	for _, st := range s.Static {
+		copied, err := s.copyDir(st)
+		files = append(files, copied...)
+		if err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+func (s *Site) render(r Route) (string, error) {
+	name, err := OutputName(r.Path)
+	if err != nil {
+		return "", err
+	}
+	data, err := r.load()
+	if err != nil {
+		return "", fmt.Errorf("site: route %s data: %v", r.Path, err)
+	}
+	buf := new(bytes.Buffer)
+	if err := s.Engine.RenderWriter(buf, r.View, data); err != nil {
+		return "", fmt.Errorf("site: route %s: %w", r.Path, err)
+	}
+	file := filepath.Join(s.Output, filepath.FromSlash(name))
+	return file, writeFile(file, buf)
+}
+
+// OutputName returns the file of a route path: `/` is `index.html`, `/about/` and `/about` are
+// `about/index.html` and a path with an extension, such as `/feed.xml`, is kept.
+func OutputName(urlPath string) (string, error) {
+	name := strings.Trim(urlPath, "/")
+	if name == "" {
+		return "index.html", nil
+	}
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("site: invalid route path %q", urlPath)
+	}
+	if strings.HasSuffix(urlPath, "/") || path.Ext(name) == "" {
+		name += "/index.html"
+	}
+	return name, nil
+}
+
+// load returns the render data of the route.
+func (r Route) load() (interface{}, error) {
+	switch {
+	case r.DataFunc != nil:
+		return r.DataFunc(r)
+	case r.Data != nil:
+		return r.Data, nil
+	case r.DataFile == "":
+		return nil, nil
+	}
+	return LoadData(r.DataFile)
+}
+
+// LoadData reads a JSON file, or a YAML file for the `.yaml` and `.yml` extensions.
+// Objects are decoded as goview.M.
+func LoadData(file string) (interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &v)
+	default:
+		err = json.Unmarshal(data, &v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return normalize(v), nil
+}
+
+// normalize converts the maps of decoded data to goview.M, yaml decodes map[interface{}]interface{}.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(goview.M, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalize(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(goview.M, len(v))
+		for k, val := range v {
+			m[k] = normalize(val)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+	}
+	return v
+}
+
+// copyDir copies the regular files of a static directory, the prefix can not escape the output.
+func (s *Site) copyDir(st Static) ([]string, error) {
+	prefix := strings.Trim(st.Prefix, "/")
+	if prefix == "" {
+		prefix = "."
+	}
+	if !fs.ValidPath(prefix) {
+		return nil, fmt.Errorf("site: invalid static prefix %q", st.Prefix)
+	}
+	var files []string
+	dest := filepath.Join(s.Output, filepath.FromSlash(prefix))
+	err := filepath.WalkDir(st.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(st.Dir, p)
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		file := filepath.Join(dest, rel)
+		if err := writeFile(file, src); err != nil {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	return files, err
+}
+
+func writeFile(file string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rel returns p relative to dir unless absolute.
+func rel(dir, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
@@ -0,0 +1,134 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"site.yaml": `
+view:
+  root: views
+  master: layouts/master
+  markdown: .md
+output: out
+static:
+  - dir: static
+    prefix: static
+routes:
+  - path: /
+    view: index
+    data: data/index.yaml
+  - path: /about
+    view: about.md
+  - path: /sitemap.xml
+    view: sitemap.xml.html
+    data: data/pages.json
+`,
+		"views/layouts/master.html": `<title>{{.title}}</title>{{template "content" .}}`,
+		"views/index.html":          `{{define "content"}}{{range .features}}<li>{{.name}}</li>{{end}}{{end}}`,
+		"views/about.md":            "---\ntitle: About\n---\n# About us",
+		"views/sitemap.xml.html":    `<urlset>{{range .}}<loc>{{.}}</loc>{{end}}</urlset>`,
+		"data/index.yaml":           "title: Home\nfeatures:\n  - name: Fast\n  - name: Small\n",
+		"data/pages.json":           `["/", "/about/"]`,
+		"static/css/app.css":        "body{}",
+		"static/img/logo.svg":       "<svg/>",
+	})
+
+	s, err := Load(filepath.Join(dir, "site.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Routes = append(s.Routes, Route{Path: "/pricing/", View: "index", DataFunc: func(r Route) (interface{}, error) {
+		return map[string]interface{}{"title": "Pricing " + r.Path}, nil
+	}})
+	files, err := s.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 6 {
+		t.Errorf("files = %v", files)
+	}
+
+	out := filepath.Join(dir, "out")
+	for name, want := range map[string]string{
+		"index.html":          "<title>Home</title><li>Fast</li><li>Small</li>",
+		"about/index.html":    "<title>About</title><h1 id=\"about-us\">About us</h1>\n",
+		"sitemap.xml":         "<urlset><loc>/</loc><loc>/about/</loc></urlset>",
+		"pricing/index.html":  "<title>Pricing /pricing/</title>",
+		"static/css/app.css":  "body{}",
+		"static/img/logo.svg": "<svg/>",
+	} {
+		got, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(name)))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"views/index.html": "index"})
+	s, err := Load(filepath.Join(dir, "missing.yaml"))
+	if err == nil {
+		t.Fatal("expected error of missing site file")
+	}
+
+	writeFiles(t, dir, map[string]string{"site.yaml": "routes:\n  - path: /\n    view: index.html\n    unknown: 1\n"})
+	if _, err = Load(filepath.Join(dir, "site.yaml")); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("expected error of unknown field, got %v", err)
+	}
+
+	writeFiles(t, dir, map[string]string{"site.yaml": "routes:\n  - path: /../x\n    view: index.html\n"})
+	if s, err = Load(filepath.Join(dir, "site.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Build(); err == nil || !strings.Contains(err.Error(), "invalid route path") {
+		t.Errorf("expected error of invalid path, got %v", err)
+	}
+
+	writeFiles(t, dir, map[string]string{"site.yaml": "static:\n  - dir: .\n    prefix: ../escaped\n"})
+	if s, err = Load(filepath.Join(dir, "site.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Build(); err == nil || !strings.Contains(err.Error(), "invalid static prefix") {
+		t.Errorf("expected error of invalid prefix, got %v", err)
+	}
+}
+
+func TestOutputName(t *testing.T) {
+	for urlPath, want := range map[string]string{
+		"/":              "index.html",
+		"":               "index.html",
+		"/about":         "about/index.html",
+		"/about/":        "about/index.html",
+		"/docs/v1.2/":    "docs/v1.2/index.html",
+		"/feed.xml":      "feed.xml",
+		"/blog/404.html": "blog/404.html",
+	} {
+		if got, err := OutputName(urlPath); err != nil || got != want {
+			t.Errorf("%q: got %q, %v, want %q", urlPath, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package goview
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStaticConfig variable
+var DefaultStaticConfig = StaticConfig{
+	Prefix: "/static/",
+	MaxAge: 365 * 24 * time.Hour,
+}
+
+// StaticConfig struct
+type StaticConfig struct {
+	Prefix string        `yaml:"prefix"` //url prefix, such as "/static/"
+	MaxAge time.Duration `yaml:"maxage"` //max-age of hashed files
+}
+
+// StaticHandler serves static files with content hashed names.
+//
+// Files are hashed at startup, `css/app.css` is also served as
+// `css/app.3f9a1c2b.css` with an immutable Cache-Control. Precompressed
+// `.gz` and `.br` variants are sent when present and accepted by the client.
+type StaticHandler struct {
+	fs     http.FileSystem
+	config StaticConfig
+	files  map[string]*staticFile //served name -> file
+	hashed map[string]string      //logical name -> hashed name
+}
+
+type staticFile struct {
+	name    string //logical name
+	etag    string
+	modTime time.Time
+	hashed  bool
+}
+
+// NewStatic creates a static handler. Any source goview supports can be used:
+// http.Dir("static"), http.FS(fsys), box.HTTPBox() of go.rice or *assetfs.AssetFS of go-bindata.
+func NewStatic(fs http.FileSystem, config StaticConfig) (*StaticHandler, error) {
+	if config.MaxAge <= 0 {
+		config.MaxAge = DefaultStaticConfig.MaxAge
+	}
+	h := &StaticHandler{
+		fs:     fs,
+		config: config,
+		files:  make(map[string]*staticFile),
+		hashed: make(map[string]string),
+	}
+	if err := h.walk("/"); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Manifest returns the logical to hashed names, for the `asset` template func:
+//
+//	engine.UseAssets(static.Manifest(false))
+func (h *StaticHandler) Manifest(dev bool) *AssetManifest {
+	return NewAssetManifest(h.config.Prefix, h.hashed, dev)
+}
+
+// ServeHTTP method
+func (h *StaticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(h.config.Prefix, "/"))
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	sf, ok := h.files[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	header := w.Header()
+	if sf.hashed {
+		header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int64(h.config.MaxAge/time.Second)))
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+	if ctype := mime.TypeByExtension(path.Ext(sf.name)); ctype != "" {
+		header.Set("Content-Type", ctype)
+	}
+
+	file, etag := sf.name, sf.etag
+	if h.hasVariant(sf.name) {
+		// the response depends on Accept-Encoding, also when the identity file is served
+		header.Add("Vary", "Accept-Encoding")
+	}
+	if enc, variant := h.variant(sf.name, r.Header.Get("Accept-Encoding")); variant != "" {
+		header.Set("Content-Encoding", enc)
+		file, etag = variant, strings.TrimSuffix(etag, `"`)+"-"+enc+`"`
+	}
+	header.Set("ETag", etag)
+
+	f, err := h.fs.Open("/" + file)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	http.ServeContent(w, r, sf.name, sf.modTime, f)
+}
+
+// hasVariant reports whether name has a precompressed variant.
+func (h *StaticHandler) hasVariant(name string) bool {
+	_, br := h.files[name+".br"]
+	_, gz := h.files[name+".gz"]
+	return br || gz
+}
+
+// variant returns the precompressed variant of name accepted by the client.
+func (h *StaticHandler) variant(name, acceptEncoding string) (encoding, file string) {
+	for _, v := range []struct{ encoding, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+		if !acceptsEncoding(acceptEncoding, v.encoding) {
+			continue
+		}
+		if _, ok := h.files[name+v.ext]; ok {
+			return v.encoding, name + v.ext
+		}
+	}
+	return "", ""
+}
+
+func (h *StaticHandler) walk(dir string) error {
+	f, err := h.fs.Open(dir)
+	if err != nil {
+		return fmt.Errorf("ViewEngine static open dir:%v, error: %v", dir, err)
+	}
+	infos, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("ViewEngine static read dir:%v, error: %v", dir, err)
+	}
+	for _, info := range infos {
+		name := path.Join(dir, info.Name())
+		if info.IsDir() {
+			if err := h.walk(name); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := h.add(strings.TrimPrefix(name, "/"), info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *StaticHandler) add(name string, modTime time.Time) error {
+	f, err := h.fs.Open("/" + name)
+	if err != nil {
+		return fmt.Errorf("ViewEngine static open file:%v, error: %v", name, err)
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return fmt.Errorf("ViewEngine static read file:%v, error: %v", name, err)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	h.files[name] = &staticFile{name: name, etag: `"` + sum[:32] + `"`, modTime: modTime}
+	if ext := path.Ext(name); ext == ".gz" || ext == ".br" {
+		// precompressed variants are only served in place of the original file
+		return nil
+	}
+
+	hashedName := hashedAssetName(name, sum[:8])
+	h.hashed[name] = hashedName
+	h.files[hashedName] = &staticFile{name: name, etag: `"` + sum[:32] + `"`, modTime: modTime, hashed: true}
+	return nil
+}
+
+// hashedAssetName inserts the hash before the extension: css/app.css -> css/app.3f9a1c2b.css
+func hashedAssetName(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header accepts the coding, by name or by `*`.
+// Coding names are case-insensitive and a coding listed with q=0 is refused, even when `*` is accepted.
+func acceptsEncoding(acceptEncoding, encoding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding != encoding && coding != "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.ToLower(strings.TrimSpace(param))
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if coding == encoding {
+			return q > 0
+		}
+		wildcard = q > 0
+	}
+	return wildcard
+}
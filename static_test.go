@@ -0,0 +1,105 @@
+package goview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"css/app.css":    {Data: []byte("body{color:red}")},
+		"css/app.css.gz": {Data: []byte("gzipped")},
+		"img/logo.png":   {Data: []byte("png")},
+	}
+	h, err := NewStatic(http.FS(fsys), StaticConfig{Prefix: "/static/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashed, ok := h.Manifest(false).Lookup("css/app.css")
+	if !ok {
+		t.Fatal("css/app.css not hashed")
+	}
+	url, _ := h.Manifest(false).URL("css/app.css")
+	if url != "/static/"+hashed {
+		t.Errorf("url = %q", url)
+	}
+	if _, ok := h.Manifest(false).Lookup("css/app.css.gz"); ok {
+		t.Error("precompressed variant must not be hashed")
+	}
+
+	req := httptest.NewRequest("GET", url, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "body{color:red}" {
+		t.Fatalf("hashed: %d %q", rec.Code, rec.Body.String())
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=31536000, immutable" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/css; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	etag := rec.Header().Get("ETag")
+	if vary := rec.Header().Get("Vary"); vary != "Accept-Encoding" {
+		t.Errorf("identity response of a file with variants: Vary = %q", vary)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/static/img/logo.png", nil))
+	if vary := rec.Header().Get("Vary"); vary != "" {
+		t.Errorf("file without variants: Vary = %q", vary)
+	}
+
+	req = httptest.NewRequest("GET", "/static/css/app.css", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("conditional: code = %d", rec.Code)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("unhashed Cache-Control = %q", cc)
+	}
+
+	req = httptest.NewRequest("GET", url, nil)
+	req.Header.Set("Accept-Encoding", "br;q=0, gzip")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Body.String() != "gzipped" {
+		t.Errorf("gzip variant: %q %q", rec.Header().Get("Content-Encoding"), rec.Body.String())
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Error("variant must have its own ETag")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/static/../css/nothing.css", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing: code = %d", rec.Code)
+	}
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	for _, tt := range []struct {
+		header, encoding string
+		want             bool
+	}{
+		{"gzip, br", "br", true},
+		{"GZIP", "gzip", true},
+		{"br;q=0.0000, gzip", "br", false},
+		{"br; Q=0.5", "br", true},
+		{"*", "br", true},
+		{"*;q=0", "gzip", false},
+		{"br;q=0, *", "br", false},
+		{"*, gzip;q=0", "gzip", false},
+		{"*, gzip;q=0", "br", true},
+		{"deflate", "gzip", false},
+		{"", "gzip", false},
+	} {
+		if got := acceptsEncoding(tt.header, tt.encoding); got != tt.want {
+			t.Errorf("acceptsEncoding(%q, %q) = %v, want %v", tt.header, tt.encoding, got, tt.want)
+		}
+	}
+}
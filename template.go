@@ -0,0 +1,113 @@
+package goview
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+// TextContentType variable
+var TextContentType = []string{"text/plain; charset=utf-8"}
+
+// tmpl is the part of html/template and text/template used by the engine.
+type tmpl interface {
+	New(name string) tmpl
+	Parse(text string) error
+	Clone() (tmpl, error)
+	Funcs(funcs map[string]interface{}) tmpl
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+}
+
+// newTmpl creates an html/template, or a text/template for NewText engines and non-HTML views.
+func (e *ViewEngine) newTmpl(name string, funcs map[string]interface{}, text bool) tmpl {
+	left, right := e.config.Delims.Left, e.config.Delims.Right
+	if text {
+		return textTmpl{texttemplate.New(name).Funcs(funcs).Delims(left, right)}
+	}
+	return htmlTmpl{htmltemplate.New(name).Funcs(funcs).Delims(left, right)}
+}
+
+// parsedTmpl is a cached template with a pool of clones ready to execute. html/template escapes
+// a template on its first execution, reusing clones escapes it once per clone instead of once per
+// render. The funcs of a clone read the render data and state from its binding.
+type parsedTmpl struct {
+	tpl     tmpl
+	text    bool
+	modTime time.Time //latest modification time of the files parsed, zero when unknown
+	pool    sync.Pool
+}
+
+// boundTmpl is a clone of a parsedTmpl with the funcs bound to its binding.
+type boundTmpl struct {
+	tpl     tmpl
+	binding *binding
+}
+
+// binding holds the data and state of the render executing a clone.
+type binding struct {
+	data interface{}
+	rs   *renderState
+}
+
+// acquire returns a clone of p bound to data and rs, release it when the render is done.
+func (e *ViewEngine) acquire(p *parsedTmpl, data interface{}, rs *renderState) (*boundTmpl, error) {
+	bt, ok := p.pool.Get().(*boundTmpl)
+	if !ok {
+		t, err := p.tpl.Clone()
+		if err != nil {
+			return nil, err
+		}
+		bt = &boundTmpl{binding: &binding{}}
+		bt.tpl = t.Funcs(e.allFuncs(bt.binding, p.text))
+	}
+	bt.binding.data, bt.binding.rs = data, rs
+	return bt, nil
+}
+
+// release returns a clone to the pool of p.
+func (p *parsedTmpl) release(bt *boundTmpl) {
+	bt.binding.data, bt.binding.rs = nil, nil
+	p.pool.Put(bt)
+}
+
+type htmlTmpl struct{ t *htmltemplate.Template }
+
+func (h htmlTmpl) New(name string) tmpl { return htmlTmpl{h.t.New(name)} }
+
+func (h htmlTmpl) Parse(text string) error {
+	_, err := h.t.Parse(text)
+	return err
+}
+
+func (h htmlTmpl) Clone() (tmpl, error) {
+	t, err := h.t.Clone()
+	return htmlTmpl{t}, err
+}
+
+func (h htmlTmpl) Funcs(funcs map[string]interface{}) tmpl { return htmlTmpl{h.t.Funcs(funcs)} }
+
+func (h htmlTmpl) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	return h.t.ExecuteTemplate(w, name, data)
+}
+
+type textTmpl struct{ t *texttemplate.Template }
+
+func (x textTmpl) New(name string) tmpl { return textTmpl{x.t.New(name)} }
+
+func (x textTmpl) Parse(text string) error {
+	_, err := x.t.Parse(text)
+	return err
+}
+
+func (x textTmpl) Clone() (tmpl, error) {
+	t, err := x.t.Clone()
+	return textTmpl{t}, err
+}
+
+func (x textTmpl) Funcs(funcs map[string]interface{}) tmpl { return textTmpl{x.t.Funcs(funcs)} }
+
+func (x textTmpl) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	return x.t.ExecuteTemplate(w, name, data)
+}
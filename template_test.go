@@ -0,0 +1,31 @@
+package goview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewText(t *testing.T) {
+	e := NewText(Config{Extension: ".tmpl", Master: "layouts/master", Minify: true, Delims: Delims{Left: "{{", Right: "}}"}})
+	files := map[string]string{
+		"layouts/master.tmpl": "Hello {{.name}},\n\n{{template \"content\" .}}\n-- \n{{include \"signature\"}}",
+		"welcome.tmpl":        `{{define "content"}}Your <b>account</b> & "profile" are ready.{{end}}`,
+		"signature.tmpl":      `The <Team>`,
+	}
+	e.SetFileHandler(func(config Config, tplFile string) (string, error) {
+		return files[tplFile+config.Extension], nil
+	})
+
+	rec := httptest.NewRecorder()
+	if err := e.Render(rec, http.StatusOK, "welcome", M{"name": "Tom & Jerry"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Hello Tom & Jerry,\n\nYour <b>account</b> & \"profile\" are ready.\n-- \nThe <Team>"
+	if rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != TextContentType[0] {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
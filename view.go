@@ -78,15 +78,22 @@ https://github.com/go-tea/goview/_examples
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/go-tea/goview/renderer"
 )
 
 // HTMLContentType variable
@@ -105,21 +112,38 @@ var DefaultConfig = Config{
 
 // ViewEngine struct
 type ViewEngine struct {
-	config      Config
-	tplMap      map[string]*template.Template
-	tplMutex    sync.RWMutex
-	fileHandler FileHandler
+	config         Config
+	tplMap         map[string]*parsedTmpl
+	mdMap          map[string]*markdownFile
+	markdown       MarkdownFunc
+	metaMap        map[string]*Meta
+	tplMutex       sync.RWMutex
+	fileHandler    FileHandler
+	modTimeHandler ModTimeHandler
+	funcPacks      []funcPack
+	funcMutex      sync.RWMutex
+	assets         *AssetManifest
+	renderer       *renderer.Renderer
+	text           bool //text/template mode, see NewText
 }
 
 // Config struct
 type Config struct {
-	Root         string           `yaml:"root"`            //view root
-	Master       string           `yaml:"master"`          //template master
-	Partials     []string         `yaml:"partials"`        //template partial, such as head, foot
-	Extension    string           `yaml:"extension"`       //template extension
-	Funcs        template.FuncMap `yaml:"funcs,omitempty"` //template functions
-	DisableCache bool             `yaml:"disablecache"`    //disable cache, debug mode
-	Delims       Delims           `yaml:"delims"`          //delimeters
+	Root         string            `yaml:"root"`            //view root
+	Master       string            `yaml:"master"`          //template master
+	Partials     []string          `yaml:"partials"`        //template partial, such as head, foot
+	Extension    string            `yaml:"extension"`       //template extension
+	Extensions   []string          `yaml:"extensions"`      //accepted template extensions in lookup order, overrides Extension
+	Funcs        template.FuncMap  `yaml:"funcs,omitempty"` //template functions
+	DisableCache bool              `yaml:"disablecache"`    //disable cache, debug mode
+	Delims       Delims            `yaml:"delims"`          //delimeters
+	CSP          string            `yaml:"csp"`             //Content-Security-Policy, `{nonce}` is replaced by a per render nonce
+	Minify       bool              `yaml:"minify"`          //remove insignificant whitespace and comments from output
+	ETag         bool              `yaml:"etag"`            //send ETag and answer If-None-Match, see RenderRequest
+	LastModified bool              `yaml:"lastmodified"`    //send Last-Modified and answer If-Modified-Since, see RenderRequest
+	FormatParam  string            `yaml:"formatparam"`     //query param choosing the format of Negotiate, such as "format"
+	ContentTypes map[string]string `yaml:"contenttypes"`    //Content-Type by view name, such as "sitemap": "application/xml"
+	Markdown     string            `yaml:"markdown"`        //extension of markdown views, such as ".md", disabled when empty
 }
 
 // M type
@@ -134,133 +158,426 @@ type Delims struct {
 // FileHandler type
 type FileHandler func(config Config, tplFile string) (content string, err error)
 
+// ModTimeHandler returns the modification time of a file of the FileHandler, called with the same
+// config and tplFile. A zero time is unknown.
+type ModTimeHandler func(config Config, tplFile string) (time.Time, error)
+
 // New function
 func New(config Config) *ViewEngine {
 	return &ViewEngine{
-		config:      config,
-		tplMap:      make(map[string]*template.Template),
-		tplMutex:    sync.RWMutex{},
-		fileHandler: DefaultFileHandler(),
+		config:         config,
+		tplMap:         make(map[string]*parsedTmpl),
+		mdMap:          make(map[string]*markdownFile),
+		metaMap:        make(map[string]*Meta),
+		tplMutex:       sync.RWMutex{},
+		fileHandler:    DefaultFileHandler(),
+		modTimeHandler: DefaultModTimeHandler(),
 	}
 }
 
+// NewText function creates an engine backed by text/template for non-HTML output,
+// such as plain-text emails or reports. Output is not escaped, the default content type
+// is TextContentType and Config.Minify is ignored.
+func NewText(config Config) *ViewEngine {
+	e := New(config)
+	e.text = true
+	return e
+}
+
 // Default function
 func Default() *ViewEngine {
 	return New(DefaultConfig)
 }
 
+// Config returns a copy of the engine config.
+func (e *ViewEngine) Config() Config {
+	return e.config
+}
+
 // Render method
 func (e *ViewEngine) Render(w http.ResponseWriter, statusCode int, name string, data interface{}) error {
-	header := w.Header()
-	if val := header["Content-Type"]; len(val) == 0 {
-		header["Content-Type"] = HTMLContentType
+	rs := new(renderState)
+	master, data, err := e.prepareView(name, data, rs)
+	if err != nil {
+		return err
 	}
+	header := w.Header()
+	e.viewHeaders(header, rs)
+	e.setNonce(header, rs)
 	w.WriteHeader(statusCode)
-	return e.executeRender(w, name, data)
+	return e.executeRender(w, name, data, master, rs)
 }
 
 // RenderWriter method
+// If w is an http.ResponseWriter, the Content-Security-Policy header is set before rendering.
 func (e *ViewEngine) RenderWriter(w io.Writer, name string, data interface{}) error {
-	return e.executeRender(w, name, data)
+	rs := new(renderState)
+	master, data, err := e.prepareView(name, data, rs)
+	if err != nil {
+		return err
+	}
+	if rw, ok := w.(http.ResponseWriter); ok {
+		e.setNonce(rw.Header(), rs)
+	}
+	return e.executeRender(w, name, data, master, rs)
 }
 
-func (e *ViewEngine) executeRender(out io.Writer, name string, data interface{}) error {
-	useMaster := true
-	if filepath.Ext(name) == e.config.Extension {
-		useMaster = false
-		name = strings.TrimSuffix(name, e.config.Extension)
+// prepareView loads the front matter and the type of the view name into rs, once per render.
+// It returns the master and the render data, the layout data for markdown views.
+func (e *ViewEngine) prepareView(name string, data interface{}, rs *renderState) (string, interface{}, error) {
+	rs.view = name
+	if e.isMarkdown(name) {
+		md, err := e.loadMarkdown(name)
+		if err != nil {
+			return "", nil, err
+		}
+		rs.meta = md.meta
+		rs.touch(md.modTime)
+		data = markdownData(md, data)
+	} else {
+		meta, err := e.meta(name)
+		if err != nil {
+			return "", nil, err
+		}
+		rs.meta = meta
+	}
+	rs.contentType, rs.text = e.viewType(name, rs.meta)
+	return e.viewMaster(name, rs), data, nil
+}
 
+// executeRender executes the view prepared by prepareView, minified when Config.Minify is set.
+func (e *ViewEngine) executeRender(out io.Writer, name string, data interface{}, master string, rs *renderState) error {
+	if e.config.Minify && !rs.text && isHTML(rs.contentType) {
+		mw := newMinifyWriter(out)
+		err := e.executeTemplate(mw, name, data, master, rs)
+		if cerr := mw.Close(); err == nil {
+			err = cerr
+		}
+		return err
 	}
-	return e.executeTemplate(out, name, data, useMaster)
+	return e.executeTemplate(out, name, data, master, rs)
 }
 
-func (e *ViewEngine) executeTemplate(out io.Writer, name string, data interface{}, useMaster bool) error {
-	var tpl *template.Template
-	var err error
-	var ok bool
+// viewMaster returns the master layout of the view name prepared in rs. A name with a template
+// extension or a view of another type than the engine default, text/html for New, renders without
+// master, otherwise the front matter layout overrides Config.Master.
+func (e *ViewEngine) viewMaster(name string, rs *renderState) string {
+	if _, _, ok := e.splitExtension(name); ok {
+		return ""
+	}
+	// a master of the engine type does not wrap other types, such as feed.xml
+	if mediaType(rs.contentType) != mediaType(e.contentType()[0]) {
+		return ""
+	}
+	if rs.meta.Layout != nil {
+		return *rs.meta.Layout
+	}
+	return e.config.Master
+}
 
-	allFuncs := make(template.FuncMap, 0)
-	allFuncs["include"] = func(layout string) (template.HTML, error) {
-		buf := new(bytes.Buffer)
-		err := e.executeTemplate(buf, layout, data, false)
-		return template.HTML(buf.String()), err
+// executeTemplate executes the template name, or master when set with name and the partials.
+func (e *ViewEngine) executeTemplate(out io.Writer, name string, data interface{}, master string, rs *renderState) error {
+	p, err := e.loadTemplate(name, master, rs)
+	if err != nil {
+		return err
 	}
 
-	// Get the plugin collection
-	for k, v := range e.config.Funcs {
-		allFuncs[k] = v
+	exeName := name
+	if master != "" {
+		exeName = master
 	}
 
-	e.tplMutex.RLock()
-	tpl, ok = e.tplMap[name]
-	e.tplMutex.RUnlock()
+	// The cached template is never executed, a clone bound to this render is.
+	bt, err := e.acquire(p, data, rs)
+	if err != nil {
+		se := new(StatusError)
+		se.Code = http.StatusInternalServerError
+		se.Err = fmt.Errorf("ViewEngine clone template error: %v", err)
+		return se
+	}
+	defer p.release(bt)
 
-	exeName := name
-	if useMaster && e.config.Master != "" {
-		exeName = e.config.Master
+	// Display the content to the screen
+	err = bt.tpl.ExecuteTemplate(out, exeName, data)
+	if err != nil {
+		se := new(StatusError)
+		se.Code = http.StatusInternalServerError
+		se.Err = fmt.Errorf("ViewEngine execute template error: %w", err)
+		return se
+		//return fmt.Errorf("ViewEngine execute template error: %v", err)
 	}
 
+	return nil
+}
+
+// loadTemplate returns the template of name with master and the partials, parsed on the first use
+// or on every use with DisableCache.
+func (e *ViewEngine) loadTemplate(name, master string, rs *renderState) (*parsedTmpl, error) {
+	key := tplKey(name, master, rs.text)
+	e.tplMutex.RLock()
+	p, ok := e.tplMap[key]
+	e.tplMutex.RUnlock()
+
 	if !ok || e.config.DisableCache {
 		tplList := make([]string, 0)
-		if useMaster {
-			//render()
-			if e.config.Master != "" {
-				tplList = append(tplList, e.config.Master)
-			}
+		if master != "" {
+			tplList = append(tplList, master)
 		}
 		tplList = append(tplList, name)
 		tplList = append(tplList, e.config.Partials...)
+		meta := rs.meta
+		if name != rs.view {
+			// an included template parses its own front matter partials
+			var err error
+			if meta, err = e.meta(name); err != nil {
+				return nil, err
+			}
+		}
+		tplList = append(tplList, meta.Partials...)
 
 		// Loop through each template and test the full path
-		tpl = template.New(name).Funcs(allFuncs).Delims(e.config.Delims.Left, e.config.Delims.Right)
+		tpl := e.newTmpl(name, e.allFuncs(&binding{}, rs.text), rs.text)
+		var modTime time.Time
+		unknown := false
 		for _, v := range tplList {
-			var data string
-			data, err = e.fileHandler(e.config, v)
+			data, modified, err := e.resolveTemplate(v)
 			if err != nil {
-				se := new(StatusError)
-				se.Code = http.StatusInternalServerError
-				se.Err = fmt.Errorf("ViewEngine fileHandler error: %v", err)
-				return se
+				return nil, fileHandlerError(err)
+			}
+			// the time of a markdown view is its file, see prepareView
+			if !e.isMarkdown(v) {
+				if modified.IsZero() {
+					unknown = true
+				} else if modified.After(modTime) {
+					modTime = modified
+				}
+			}
+			if e.hasFrontMatter(v) {
+				_, data, _ = splitFrontMatter(data)
 			}
-			var tmpl *template.Template
-			if v == name {
-				tmpl = tpl
-			} else {
-				tmpl = tpl.New(v)
+			t := tpl
+			if v != name {
+				t = tpl.New(v)
 			}
-			_, err = tmpl.Parse(data)
+			err = t.Parse(data)
 			if err != nil {
 				se := new(StatusError)
 				se.Code = http.StatusInternalServerError
 				se.Err = fmt.Errorf("ViewEngine render parser name:%v, error: %v", v, err)
-				return se
-				//	return fmt.Errorf("ViewEngine render parser name:%v, error: %v", v, err)
+				return nil, se
 			}
 		}
+		if unknown {
+			modTime = time.Time{}
+		}
+		p = &parsedTmpl{tpl: tpl, text: rs.text, modTime: modTime}
 		e.tplMutex.Lock()
-		e.tplMap[name] = tpl
+		e.tplMap[key] = p
 		e.tplMutex.Unlock()
 	}
+	rs.touch(p.modTime)
+	return p, nil
+}
 
-	// Display the content to the screen
-	err = tpl.Funcs(allFuncs).ExecuteTemplate(out, exeName, data)
+// Parse parses the view name with its master and partials without rendering it. The escaping
+// errors of html/template, such as a template ending inside a tag, are reported by executing
+// the view with nil data, other execution errors are ignored.
+func (e *ViewEngine) Parse(name string) error {
+	rs := new(renderState)
+	master, data, err := e.prepareView(name, nil, rs)
 	if err != nil {
+		return err
+	}
+	p, err := e.loadTemplate(name, master, rs)
+	if err != nil || rs.text {
+		return err
+	}
+
+	exeName := name
+	if master != "" {
+		exeName = master
+	}
+	bt, err := e.acquire(p, data, rs)
+	if err != nil {
+		return err
+	}
+	defer p.release(bt)
+	var escapeErr *template.Error
+	if err := bt.tpl.ExecuteTemplate(io.Discard, exeName, data); errors.As(err, &escapeErr) {
 		se := new(StatusError)
 		se.Code = http.StatusInternalServerError
-		se.Err = fmt.Errorf("ViewEngine execute template error: %v", err)
+		se.Err = fmt.Errorf("ViewEngine render parser name:%v, error: %w", name, escapeErr)
 		return se
-		//return fmt.Errorf("ViewEngine execute template error: %v", err)
 	}
-
 	return nil
 }
 
+// builtinFuncs returns the funcs provided by goview, reading the render data and state from b
+// when called. include returns a string instead of template.HTML for text templates, and json
+// a string instead of template.JS.
+func (e *ViewEngine) builtinFuncs(b *binding, text bool) template.FuncMap {
+	var include interface{} = func(layout string) (template.HTML, error) {
+		buf := new(bytes.Buffer)
+		err := e.executeTemplate(buf, layout, b.data, "", b.rs)
+		return template.HTML(buf.String()), err
+	}
+	var toJSON interface{} = func(v interface{}) (template.JS, error) {
+		bs, err := json.Marshal(v)
+		return template.JS(bs), err
+	}
+	if text {
+		include = func(layout string) (string, error) {
+			buf := new(bytes.Buffer)
+			err := e.executeTemplate(buf, layout, b.data, "", b.rs)
+			return buf.String(), err
+		}
+		toJSON = func(v interface{}) (string, error) {
+			bs, err := json.Marshal(v)
+			return string(bs), err
+		}
+	}
+	return template.FuncMap{
+		"include": include,
+		// json and js escape values in JSON and JavaScript views, rendered by text/template
+		"json": toJSON,
+		"js": func(v interface{}) string {
+			return template.JSEscapeString(fmt.Sprint(v))
+		},
+		"cspNonce": func() string {
+			return b.rs.nonce
+		},
+		"meta": func(key ...string) interface{} {
+			if b.rs.meta == nil {
+				b.rs.meta = new(Meta)
+			}
+			if len(key) == 0 {
+				return b.rs.meta
+			}
+			return b.rs.meta.Get(key[0])
+		},
+	}
+}
+
+// tplKey returns the cache key of a template, the same view is parsed once per master and escaping mode.
+func tplKey(name, master string, text bool) string {
+	key := name
+	if master != "" {
+		key = master + ":" + key
+	}
+	if text {
+		key = "text:" + key
+	}
+	return key
+}
+
+// contentType returns the default Content-Type of the engine.
+func (e *ViewEngine) contentType() []string {
+	if e.text {
+		return TextContentType
+	}
+	return HTMLContentType
+}
+
+// extensions returns the accepted template extensions in lookup order.
+func (e *ViewEngine) extensions() []string {
+	if len(e.config.Extensions) > 0 {
+		return e.config.Extensions
+	}
+	return []string{e.config.Extension}
+}
+
+// splitExtension strips an accepted extension from a render name, a name with any
+// accepted extension renders without master. Without one, ext is the first extension.
+func (e *ViewEngine) splitExtension(name string) (base, ext string, ok bool) {
+	exts := e.extensions()
+	if nameExt := filepath.Ext(name); nameExt != "" {
+		for _, ext := range exts {
+			if nameExt == ext {
+				return strings.TrimSuffix(name, ext), ext, true
+			}
+		}
+	}
+	return name, exts[0], false
+}
+
+// readTemplate reads a template with the file handler. A name with an accepted extension is
+// read as is, otherwise each accepted extension is tried in order and the error of the first
+// extension is returned when none is found. The first extension wins when a view exists under
+// several. The lookup is cached with the template, with DisableCache it runs on every render and
+// a view of a later extension costs a failed read per earlier extension.
+func (e *ViewEngine) readTemplate(name string) (string, error) {
+	content, _, err := e.resolveTemplate(name)
+	return content, err
+}
+
+// resolveTemplate is readTemplate, it also returns the modification time of the file read,
+// zero when unknown, see SetModTimeHandler.
+func (e *ViewEngine) resolveTemplate(name string) (string, time.Time, error) {
+	if e.isMarkdown(name) {
+		return e.markdownSource(), time.Time{}, nil
+	}
+	config := e.config
+	if base, ext, ok := e.splitExtension(name); ok {
+		config.Extension = ext
+		content, err := e.fileHandler(config, base)
+		return content, e.fileModTime(config, base), err
+	}
+	var firstErr error
+	for _, ext := range e.extensions() {
+		config.Extension = ext
+		content, err := e.fileHandler(config, name)
+		if err == nil {
+			return content, e.fileModTime(config, name), nil
+		}
+		// only a missing file falls back to the next extension
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", time.Time{}, err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return "", time.Time{}, firstErr
+}
+
+// fileModTime returns the modification time of a file read with config, zero when unknown.
+func (e *ViewEngine) fileModTime(config Config, tplFile string) time.Time {
+	if e.modTimeHandler == nil {
+		return time.Time{}
+	}
+	t, err := e.modTimeHandler(config, tplFile)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// readFile reads a non-template file under Root, such as an asset manifest, with the file handler.
+func (e *ViewEngine) readFile(name string) ([]byte, error) {
+	config := e.config
+	config.Extension = ""
+	content, err := e.fileHandler(config, name)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
 // SetFileHandler method
+// The template modification times are unknown with another file handler until SetModTimeHandler,
+// RenderRequest sends no Last-Modified without them.
 func (e *ViewEngine) SetFileHandler(handle FileHandler) {
 	if handle == nil {
 		panic("FileHandler can't set nil!")
 	}
 	e.fileHandler = handle
+	e.modTimeHandler = nil
+}
+
+// SetModTimeHandler sets the handler returning the modification times of the files of the
+// file handler, used for Last-Modified by RenderRequest. nil makes them unknown.
+func (e *ViewEngine) SetModTimeHandler(handle ModTimeHandler) {
+	e.modTimeHandler = handle
 }
 
 // DefaultFileHandler function
@@ -273,8 +590,56 @@ func DefaultFileHandler() FileHandler {
 		}
 		data, err := ioutil.ReadFile(path)
 		if err != nil {
-			return "", fmt.Errorf("ViewEngine render read name:%v, path:%v, error: %v", tplFile, path, err)
+			return "", fmt.Errorf("ViewEngine render read name:%v, path:%v, error: %w", tplFile, path, err)
+		}
+		return string(data), nil
+	}
+}
+
+// DefaultModTimeHandler returns the modification times of the files of DefaultFileHandler.
+func DefaultModTimeHandler() ModTimeHandler {
+	return func(config Config, tplFile string) (time.Time, error) {
+		info, err := os.Stat(config.Root + string(os.PathSeparator) + tplFile + config.Extension)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return info.ModTime(), nil
+	}
+}
+
+// FSModTimeHandler returns the modification times of the files of FSFileHandler, embed.FS files
+// have none.
+func FSModTimeHandler(fsys fs.FS) ModTimeHandler {
+	return func(config Config, tplFile string) (time.Time, error) {
+		info, err := fs.Stat(fsys, path.Join(config.Root, tplFile+config.Extension))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return info.ModTime(), nil
+	}
+}
+
+// FSFileHandler function support fs.FS file handler, such as embed.FS
+func FSFileHandler(fsys fs.FS) FileHandler {
+	return func(config Config, tplFile string) (content string, err error) {
+		name := path.Join(config.Root, tplFile+config.Extension)
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return "", fmt.Errorf("ViewEngine render read name:%v, path:%v, error: %w", tplFile, name, err)
 		}
 		return string(data), nil
 	}
 }
+
+// MapFileHandler function support templates in a map keyed by their path relative to Root, with extension,
+// such as the Files generated by `goview generate`.
+func MapFileHandler(files map[string]string) FileHandler {
+	return func(config Config, tplFile string) (content string, err error) {
+		name := path.Clean(tplFile + config.Extension)
+		content, ok := files[name]
+		if !ok {
+			return "", fmt.Errorf("ViewEngine render read name:%v, path:%v, error: %w", tplFile, name, fs.ErrNotExist)
+		}
+		return content, nil
+	}
+}
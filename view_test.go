@@ -1,9 +1,14 @@
 package goview
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"net/http"
+	"strings"
+	"testing"
 	"time"
 )
 
@@ -108,3 +113,120 @@ func ExampleNew() {
 	fmt.Println("Listening and serving HTTP on :9090")
 	http.ListenAndServe(":9090", nil)
 }
+
+// newTestEngine returns an engine reading templates from files, keyed by name with extension.
+func newTestEngine(config Config, files map[string]string) *ViewEngine {
+	e := New(config)
+	e.SetFileHandler(func(config Config, tplFile string) (string, error) {
+		content, ok := files[tplFile+config.Extension]
+		if !ok {
+			return "", fmt.Errorf("file %s%s: %w", tplFile, config.Extension, fs.ErrNotExist)
+		}
+		return content, nil
+	})
+	return e
+}
+
+func TestExtensions(t *testing.T) {
+	e := newTestEngine(Config{
+		Extensions: []string{".html", ".tmpl", ".gohtml"},
+		Master:     "layouts/master",
+	}, map[string]string{
+		"layouts/master.gohtml": `<main>{{template "content" .}}</main>`,
+		"index.tmpl":            `{{define "content"}}index{{end}}`,
+		"page.html":             `page`,
+		"page.tmpl":             `shadowed`,
+		"footer.gohtml":         `footer`,
+	})
+
+	for name, want := range map[string]string{
+		"index":         "<main>index</main>",
+		"index.tmpl":    "",
+		"page.html":     "page",
+		"page.tmpl":     "shadowed",
+		"footer.gohtml": "footer",
+	} {
+		buf := new(bytes.Buffer)
+		if err := e.RenderWriter(buf, name, nil); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if buf.String() != want {
+			t.Errorf("%s: got %q, want %q", name, buf.String(), want)
+		}
+	}
+	if err := e.RenderWriter(new(bytes.Buffer), "missing", nil); !errors.Is(err, ErrTemplateNotFound) || !strings.Contains(err.Error(), "missing.html") {
+		t.Errorf("missing: err = %v", err)
+	}
+}
+
+func TestExtensionReadError(t *testing.T) {
+	e := New(Config{Extensions: []string{".html", ".tmpl"}})
+	e.SetFileHandler(func(config Config, tplFile string) (string, error) {
+		if config.Extension == ".html" {
+			return "", fmt.Errorf("file %s%s: %w", tplFile, config.Extension, fs.ErrPermission)
+		}
+		return "tmpl", nil
+	})
+	// a file that can not be read does not fall back to the next extension
+	if err := e.RenderWriter(new(bytes.Buffer), "page", nil); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("err = %v, want %v", err, fs.ErrPermission)
+	}
+}
+
+func TestExtensionPrecedence(t *testing.T) {
+	files := map[string]string{
+		"page.html": `html`,
+		"page.tmpl": `tmpl`,
+	}
+	for _, tt := range []struct {
+		exts []string
+		want string
+	}{
+		{[]string{".html", ".tmpl"}, "html"},
+		{[]string{".tmpl", ".html"}, "tmpl"},
+	} {
+		// the lookup order also holds when templates are read on every render
+		for _, disableCache := range []bool{false, true} {
+			e := newTestEngine(Config{Extensions: tt.exts, DisableCache: disableCache}, files)
+			buf := new(bytes.Buffer)
+			if err := e.RenderWriter(buf, "page", nil); err != nil {
+				t.Fatalf("%v: %v", tt.exts, err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("%v, DisableCache %v: got %q, want %q", tt.exts, disableCache, buf.String(), tt.want)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	e := newTestEngine(Config{Extension: ".html", Master: "layouts/master"}, map[string]string{
+		"layouts/master.html": `<main>{{template "content" .}}</main>`,
+		"index.html":          `{{define "content"}}{{.Missing.Field}}{{end}}`,
+		"syntax.html":         `{{if}}`,
+		"attr.html":           `<a href="{{.}}`,
+	})
+	if err := e.Parse("index"); err != nil {
+		t.Errorf("index: %v", err)
+	}
+	for _, name := range []string{"syntax.html", "attr.html", "missing"} {
+		if err := e.Parse(name); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestMapFileHandler(t *testing.T) {
+	e := New(Config{Extension: ".html", Master: "layouts/master"})
+	e.SetFileHandler(MapFileHandler(map[string]string{
+		"layouts/master.html": `<main>{{template "content" .}}</main>`,
+		"index.html":          `{{define "content"}}{{.}}{{end}}`,
+	}))
+	buf := new(bytes.Buffer)
+	if err := e.RenderWriter(buf, "index", "home"); err != nil || buf.String() != "<main>home</main>" {
+		t.Errorf("got %q, %v", buf.String(), err)
+	}
+	if err := e.RenderWriter(buf, "missing", nil); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("missing: err = %v", err)
+	}
+}